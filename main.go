@@ -1,9 +1,11 @@
 package main
 
-import "github.com/coding-for-fun-org/lazygithub/pkg/cli_prompt"
+import (
+	"os"
 
-func main() {
-	c := cli_prompt.CreatePullRequest{}
+	"github.com/coding-for-fun-org/lazygithub/pkg/cli_command"
+)
 
-	c.Run()
+func main() {
+	os.Exit(cli_command.Execute(os.Args[1:], os.Stderr))
 }
@@ -0,0 +1,169 @@
+package cli_command
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// GlobalOptions struct to represent the flags that every command accepts
+type GlobalOptions struct {
+	// Repo is "owner/name", or "" for the repository of the current directory
+	Repo     string
+	Hostname string
+	Verbose  bool
+}
+
+// register method to define the global flags on a flag set, keeping the values parsed by the parent commands
+func (g *GlobalOptions) register(fs *flag.FlagSet) {
+	fs.StringVar(&g.Repo, "repo", g.Repo, "Select another repository using the OWNER/NAME format")
+	fs.StringVar(&g.Hostname, "hostname", g.Hostname, "The GitHub hostname to use, detected from the git remotes by default")
+	fs.BoolVar(&g.Verbose, "verbose", g.Verbose, "Print the commands that are run")
+}
+
+// Command struct to represent a node of the command tree.
+// A command either runs something or groups subcommands.
+type Command struct {
+	Name string
+	// ArgsUsage describes the positional arguments, e.g. "<number>"
+	ArgsUsage string
+	Summary   string
+	// Flags defines the command specific flags on the flag set
+	Flags       func(fs *flag.FlagSet)
+	Run         func(ctx context.Context, globals *GlobalOptions, args []string) error
+	Subcommands []*Command
+}
+
+// usageError struct to represent a mistake in the command line, the usage has already been printed
+type usageError struct {
+	message string
+}
+
+func (e *usageError) Error() string {
+	return e.message
+}
+
+// errHelp is returned when the help was asked for and printed
+var errHelp = errors.New("help requested")
+
+// findSubcommand method to find a direct subcommand by name
+func (c *Command) findSubcommand(name string) *Command {
+	for _, subcommand := range c.Subcommands {
+		if subcommand.Name == name {
+			return subcommand
+		}
+	}
+
+	return nil
+}
+
+// printUsage method to print the help text of the command
+func (c *Command) printUsage(w io.Writer, path []string, fs *flag.FlagSet) {
+	usage := strings.Join(path, " ")
+	if len(c.Subcommands) > 0 {
+		usage += " <command>"
+	}
+	if c.ArgsUsage != "" {
+		usage += " " + c.ArgsUsage
+	}
+
+	if c.Summary != "" {
+		fmt.Fprintf(w, "%s\n\n", c.Summary)
+	}
+	fmt.Fprintf(w, "Usage:\n  %s [flags]\n", usage)
+
+	if len(c.Subcommands) > 0 {
+		fmt.Fprintln(w, "\nCommands:")
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		for _, subcommand := range c.Subcommands {
+			fmt.Fprintf(tw, "  %s\t%s\n", subcommand.Name, subcommand.Summary)
+		}
+		tw.Flush()
+	}
+
+	fmt.Fprintln(w, "\nFlags:")
+	fs.SetOutput(w)
+	fs.PrintDefaults()
+}
+
+// execute method to parse the flags of the command and run it or dispatch to a subcommand
+func (c *Command) execute(ctx context.Context, path []string, globals *GlobalOptions, args []string, stderr io.Writer) error {
+	path = append(path, c.Name)
+
+	fs := flag.NewFlagSet(strings.Join(path, " "), flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	globals.register(fs)
+	if c.Flags != nil {
+		c.Flags(fs)
+	}
+
+	if err := fs.Parse(args); err != nil {
+		c.printUsage(stderr, path, fs)
+		if errors.Is(err, flag.ErrHelp) {
+			return errHelp
+		}
+		return &usageError{message: err.Error()}
+	}
+	rest := fs.Args()
+
+	if len(c.Subcommands) > 0 && len(rest) > 0 {
+		if rest[0] == "help" {
+			return c.help(ctx, path, globals, rest[1:], stderr)
+		}
+		if subcommand := c.findSubcommand(rest[0]); subcommand != nil {
+			return subcommand.execute(ctx, path, globals, rest[1:], stderr)
+		}
+		if c.Run == nil {
+			c.printUsage(stderr, path, fs)
+			return &usageError{message: fmt.Sprintf("unknown command %q for %q", rest[0], strings.Join(path, " "))}
+		}
+	}
+
+	if c.Run == nil {
+		c.printUsage(stderr, path, fs)
+		return &usageError{message: fmt.Sprintf("%q requires a subcommand", strings.Join(path, " "))}
+	}
+
+	err := c.Run(ctx, globals, rest)
+	var usage *usageError
+	if errors.As(err, &usage) {
+		c.printUsage(stderr, path, fs)
+	}
+
+	return err
+}
+
+// help method to print the help text of a subcommand, e.g. "lazygithub help pr create"
+func (c *Command) help(ctx context.Context, path []string, globals *GlobalOptions, names []string, stderr io.Writer) error {
+	command := c
+	for _, name := range names {
+		subcommand := command.findSubcommand(name)
+		if subcommand == nil {
+			return &usageError{message: fmt.Sprintf("unknown help topic %q", strings.Join(names, " "))}
+		}
+		path = append(path, name)
+		command = subcommand
+	}
+
+	fs := flag.NewFlagSet(strings.Join(path, " "), flag.ContinueOnError)
+	globals.register(fs)
+	if command.Flags != nil {
+		command.Flags(fs)
+	}
+	command.printUsage(stderr, path, fs)
+
+	return errHelp
+}
+
+// exactArgs function to check the number of positional arguments of a command
+func exactArgs(args []string, n int) error {
+	if len(args) != n {
+		return &usageError{message: fmt.Sprintf("expected %d argument(s) but got %d", n, len(args))}
+	}
+
+	return nil
+}
@@ -0,0 +1,141 @@
+package cli_command
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"reflect"
+	"testing"
+)
+
+func TestCommand_execute(t *testing.T) {
+	var gotArgs []string
+	var gotGlobals GlobalOptions
+	var gotName string
+	newTree := func() *Command {
+		return &Command{
+			Name: "root",
+			Subcommands: []*Command{
+				{
+					Name: "group",
+					Subcommands: []*Command{
+						{
+							Name: "leaf",
+							Flags: func(fs *flag.FlagSet) {
+								fs.StringVar(&gotName, "name", "", "")
+							},
+							Run: func(ctx context.Context, globals *GlobalOptions, args []string) error {
+								gotArgs = args
+								gotGlobals = *globals
+								return nil
+							},
+						},
+						{
+							Name: "fail",
+							Run: func(ctx context.Context, globals *GlobalOptions, args []string) error {
+								return errors.New("failed")
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		args        []string
+		wantErr     error
+		wantUsage   bool
+		wantArgs    []string
+		wantGlobals GlobalOptions
+		wantName    string
+	}{
+		{
+			name:        "global flags on any level",
+			args:        []string{"--repo", "owner/repo", "group", "--verbose", "leaf", "--hostname", "github.example.com", "--name", "x", "arg"},
+			wantArgs:    []string{"arg"},
+			wantGlobals: GlobalOptions{Repo: "owner/repo", Hostname: "github.example.com", Verbose: true},
+			wantName:    "x",
+		},
+		{
+			name:    "help flag",
+			args:    []string{"group", "leaf", "-h"},
+			wantErr: errHelp,
+		},
+		{
+			name:    "help command",
+			args:    []string{"help", "group", "leaf"},
+			wantErr: errHelp,
+		},
+		{
+			name:      "missing subcommand",
+			args:      []string{"group"},
+			wantUsage: true,
+		},
+		{
+			name:      "unknown subcommand",
+			args:      []string{"group", "unknown"},
+			wantUsage: true,
+		},
+		{
+			name:      "unknown flag",
+			args:      []string{"group", "leaf", "--unknown"},
+			wantUsage: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotArgs, gotGlobals, gotName = nil, GlobalOptions{}, ""
+			var stderr bytes.Buffer
+
+			err := newTree().execute(context.Background(), []string{}, &GlobalOptions{}, tt.args, &stderr)
+
+			var usage *usageError
+			if errors.As(err, &usage) != tt.wantUsage {
+				t.Errorf("Command.execute() error = %v, wantUsage %v", err, tt.wantUsage)
+				return
+			}
+			if tt.wantUsage {
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Command.execute() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if !reflect.DeepEqual(gotArgs, tt.wantArgs) {
+				t.Errorf("Command.execute() args = %v, want %v", gotArgs, tt.wantArgs)
+			}
+			if gotGlobals != tt.wantGlobals {
+				t.Errorf("Command.execute() globals = %v, want %v", gotGlobals, tt.wantGlobals)
+			}
+			if gotName != tt.wantName {
+				t.Errorf("Command.execute() name = %v, want %v", gotName, tt.wantName)
+			}
+		})
+	}
+}
+
+func TestExecute(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{name: "help", args: []string{"--help"}, want: ExitOK},
+		{name: "unknown command", args: []string{"unknown"}, want: ExitUsage},
+		{name: "missing argument", args: []string{"pr", "view"}, want: ExitUsage},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stderr bytes.Buffer
+			if got := Execute(tt.args, &stderr); got != tt.want {
+				t.Errorf("Execute() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package cli_command
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/coding-for-fun-org/lazygithub/pkg/config"
+)
+
+// newConfigCommand function to build the "config" command group
+func newConfigCommand() *Command {
+	return &Command{
+		Name:    "config",
+		Summary: "Inspect the lazygithub configuration",
+		Subcommands: []*Command{
+			{
+				Name:    "path",
+				Summary: "Print the path of the config file",
+				Run: func(ctx context.Context, globals *GlobalOptions, args []string) error {
+					if err := exactArgs(args, 0); err != nil {
+						return err
+					}
+
+					path, err := config.Path()
+					if err != nil {
+						return err
+					}
+					fmt.Println(path)
+
+					return nil
+				},
+			},
+			{
+				Name:    "show",
+				Summary: "Print the loaded configuration",
+				Run: func(ctx context.Context, globals *GlobalOptions, args []string) error {
+					if err := exactArgs(args, 0); err != nil {
+						return err
+					}
+
+					c, err := config.Load()
+					if err != nil {
+						return err
+					}
+					content, err := json.MarshalIndent(c, "", "  ")
+					if err != nil {
+						return err
+					}
+					fmt.Println(string(content))
+
+					return nil
+				},
+			},
+		},
+	}
+}
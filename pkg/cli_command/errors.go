@@ -0,0 +1,59 @@
+package cli_command
+
+import (
+	"errors"
+
+	"github.com/coding-for-fun-org/lazygithub/pkg/command_runner"
+	"github.com/coding-for-fun-org/lazygithub/pkg/gh_command"
+)
+
+// errorHints holds what the user can do about each kind of failure
+var errorHints = []struct {
+	kind error
+	hint string
+}{
+	{command_runner.ErrNotAuthenticated, "Run `gh auth login` to authenticate with GitHub."},
+	{command_runner.ErrNotGitRepository, "Run lazygithub inside a git repository."},
+	{command_runner.ErrRepoNotFound, "Check the --repo flag and the git remotes, and that you have access to the repository."},
+	{command_runner.ErrRateLimited, "The GitHub API rate limit was exceeded, wait for it to reset and try again."},
+	{command_runner.ErrMissingScopes, "Run `gh auth refresh --scopes <scope>` with the scope named in the error."},
+	{command_runner.ErrJSONDecode, "The output of the command was not understood, please update gh or report the issue."},
+	{command_runner.ErrNotFound, "Check that the branches, pull request or issue exist on GitHub."},
+}
+
+// describeError function to build the message shown for an error,
+// including a hint on how to fix it and the stderr of the command that failed
+func describeError(err error) string {
+	message := err.Error()
+
+	var commandErr *command_runner.CommandError
+	isCommandErr := errors.As(err, &commandErr)
+
+	if errors.Is(err, command_runner.ErrNotInstalled) && isCommandErr {
+		if commandErr.Name == "gh" {
+			message += "\n\nInstall the GitHub CLI from https://cli.github.com and make sure it is in your PATH."
+		} else {
+			message += "\n\nInstall " + commandErr.Name + " and make sure it is in your PATH."
+		}
+	}
+
+	for _, errorHint := range errorHints {
+		if errors.Is(err, errorHint.kind) {
+			message += "\n\n" + errorHint.hint
+			break
+		}
+	}
+
+	var graphqlErr *gh_command.GraphQLError
+	stderr := ""
+	if isCommandErr {
+		stderr = commandErr.Stderr
+	} else if errors.As(err, &graphqlErr) {
+		stderr = graphqlErr.Stderr
+	}
+	if stderr != "" {
+		message += "\n\n" + stderr
+	}
+
+	return message
+}
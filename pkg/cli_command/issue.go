@@ -0,0 +1,86 @@
+package cli_command
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/coding-for-fun-org/lazygithub/pkg/gh_command"
+)
+
+// newIssueCommand function to build the "issue" command group
+func newIssueCommand() *Command {
+	return &Command{
+		Name:    "issue",
+		Summary: "Work with issues",
+		Subcommands: []*Command{
+			newIssueListCommand(),
+			newIssueViewCommand(),
+		},
+	}
+}
+
+// newIssueListCommand function to build the "issue list" command
+func newIssueListCommand() *Command {
+	options := gh_command.ListOptions{}
+
+	return &Command{
+		Name:    "list",
+		Summary: "List the issues of the repository",
+		Flags:   listFlags(&options, "open, closed or all"),
+		Run: func(ctx context.Context, globals *GlobalOptions, args []string) error {
+			if err := exactArgs(args, 0); err != nil {
+				return err
+			}
+			if err := applyGlobalOptions(globals); err != nil {
+				return err
+			}
+			options.Hostname = globals.Hostname
+			options.RepoName = globals.Repo
+
+			issues, err := gh_command.ListIssues(ctx, options)
+			if err != nil {
+				return err
+			}
+
+			tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			for _, issue := range issues {
+				fmt.Fprintf(tw, "#%d\t%s\t%s\t%s\n", issue.Number, issue.Title, issue.Author.Login, issue.State)
+			}
+
+			return tw.Flush()
+		},
+	}
+}
+
+// newIssueViewCommand function to build the "issue view" command
+func newIssueViewCommand() *Command {
+	return &Command{
+		Name:      "view",
+		ArgsUsage: "<number | url>",
+		Summary:   "Show the detail of an issue",
+		Run: func(ctx context.Context, globals *GlobalOptions, args []string) error {
+			if err := exactArgs(args, 1); err != nil {
+				return err
+			}
+			if err := applyGlobalOptions(globals); err != nil {
+				return err
+			}
+
+			issue, err := gh_command.GetIssue(ctx, globals.Hostname, globals.Repo, args[0])
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("#%d %s\n", issue.Number, issue.Title)
+			fmt.Printf("%s • opened by %s\n", issue.State, issue.Author.Login)
+			fmt.Println(issue.URL)
+			if issue.Body != "" {
+				fmt.Printf("\n%s\n", issue.Body)
+			}
+
+			return nil
+		},
+	}
+}
@@ -0,0 +1,236 @@
+package cli_command
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/coding-for-fun-org/lazygithub/pkg/cli_prompt"
+	"github.com/coding-for-fun-org/lazygithub/pkg/gh_command"
+)
+
+// newPullRequestCommand function to build the "pr" command group
+func newPullRequestCommand(create *Command) *Command {
+	return &Command{
+		Name:    "pr",
+		Summary: "Work with pull requests",
+		Subcommands: []*Command{
+			create,
+			newPullRequestStackCommand(),
+			newPullRequestListCommand(),
+			newPullRequestViewCommand(),
+		},
+	}
+}
+
+// newPullRequestCreateCommand function to build the "pr create" command
+func newPullRequestCreateCommand() *Command {
+	options := cli_prompt.CreatePullRequestOptions{}
+	var reviewers string
+	var draft bool
+	var push bool
+	var maintainerEdit bool
+	var fs *flag.FlagSet
+
+	return &Command{
+		Name:    "create",
+		Summary: "Create a pull request or edit the open one of the head branch, prompting for what is not given",
+		Flags: func(flags *flag.FlagSet) {
+			fs = flags
+			fs.StringVar(&options.Head, "head", "", "The branch that contains the changes")
+			fs.StringVar(&options.Base, "base", "", "The branch the changes are merged into")
+			fs.StringVar(&options.Title, "title", "", "The title of the pull request")
+			fs.StringVar(&options.Body, "body", "", "The body of the pull request")
+			fs.StringVar(&options.BodyFile, "body-file", "", "Read the body of the pull request from a file (use \"-\" for stdin)")
+			fs.BoolVar(&options.Editor, "editor", false, "Write the body in $VISUAL or $EDITOR instead of the form")
+			fs.StringVar(&options.Template, "template", "", "The pull request template relative to the repository root (use \"none\" for no template)")
+			fs.StringVar(&reviewers, "reviewers", "", "Comma separated logins of the reviewers, or ORG/TEAM for team reviewers")
+			fs.BoolVar(&draft, "draft", false, "Create the pull request as a draft")
+			fs.BoolVar(&push, "push", false, "Push the head branch when the remote does not have its latest commits, force pushing with lease after a rebase (use --push=false to never push)")
+			fs.BoolVar(&maintainerEdit, "maintainer-edit", true, "Allow the maintainers of the base repository to push to the head branch of a fork")
+			fs.BoolVar(&options.DryRun, "dry-run", false, "Print the pull request as JSON instead of creating or updating it")
+			fs.BoolVar(&options.Yes, "yes", false, "Accept the prepopulated or default values without prompting")
+		},
+		Run: func(ctx context.Context, globals *GlobalOptions, args []string) error {
+			if err := exactArgs(args, 0); err != nil {
+				return err
+			}
+			if err := applyGlobalOptions(globals); err != nil {
+				return err
+			}
+
+			// Only the flags that were actually given should skip the prompts.
+			// fs is nil when "pr create" is run as the default command of the root.
+			if fs != nil {
+				fs.Visit(func(f *flag.Flag) {
+					switch f.Name {
+					case "reviewers":
+						options.Reviewers = splitReviewers(reviewers)
+					case "draft":
+						options.Draft = &draft
+					case "push":
+						options.Push = &push
+					case "maintainer-edit":
+						options.MaintainerCanModify = &maintainerEdit
+					}
+				})
+			}
+			options.Hostname = globals.Hostname
+			options.Repo = globals.Repo
+
+			return cli_prompt.NewCreatePullRequest(options).Run(ctx)
+		},
+	}
+}
+
+// newPullRequestStackCommand function to build the "pr stack" command
+func newPullRequestStackCommand() *Command {
+	options := cli_prompt.StackPullRequestsOptions{}
+	var draft bool
+	var push bool
+	var fs *flag.FlagSet
+
+	return &Command{
+		Name:    "stack",
+		Summary: "Create or update one pull request per branch of the stack ending at the head branch, each based on the branch below it",
+		Flags: func(flags *flag.FlagSet) {
+			fs = flags
+			fs.StringVar(&options.Head, "head", "", "The top branch of the stack (default: the checked out branch)")
+			fs.StringVar(&options.Base, "base", "", "The branch the bottom of the stack is merged into (default: the default branch)")
+			fs.BoolVar(&draft, "draft", false, "Create the pull requests as drafts")
+			fs.BoolVar(&push, "push", false, "Push the branches when the remote does not have their latest commits, force pushing with lease after a rebase (use --push=false to never push)")
+			fs.BoolVar(&options.Yes, "yes", false, "Accept the prepopulated or default values without prompting")
+		},
+		Run: func(ctx context.Context, globals *GlobalOptions, args []string) error {
+			if err := exactArgs(args, 0); err != nil {
+				return err
+			}
+			if err := applyGlobalOptions(globals); err != nil {
+				return err
+			}
+
+			fs.Visit(func(f *flag.Flag) {
+				switch f.Name {
+				case "draft":
+					options.Draft = &draft
+				case "push":
+					options.Push = &push
+				}
+			})
+			options.Hostname = globals.Hostname
+			options.Repo = globals.Repo
+
+			return cli_prompt.NewStackPullRequests(options).Run(ctx)
+		},
+	}
+}
+
+// splitReviewers function to split the comma separated reviewers, ignoring empty entries
+func splitReviewers(value string) []string {
+	reviewers := make([]string, 0)
+	for _, reviewer := range strings.Split(value, ",") {
+		reviewer = strings.TrimSpace(reviewer)
+		if reviewer != "" {
+			reviewers = append(reviewers, reviewer)
+		}
+	}
+
+	return reviewers
+}
+
+// listFlags function to define the flags shared by the list commands
+func listFlags(options *gh_command.ListOptions, states string) func(fs *flag.FlagSet) {
+	return func(fs *flag.FlagSet) {
+		fs.StringVar(&options.State, "state", "", "Filter by state: "+states)
+		fs.IntVar(&options.Limit, "limit", 30, "Maximum number of items to list")
+	}
+}
+
+// newPullRequestListCommand function to build the "pr list" command
+func newPullRequestListCommand() *Command {
+	options := gh_command.ListOptions{}
+
+	return &Command{
+		Name:    "list",
+		Summary: "List the pull requests of the repository",
+		Flags:   listFlags(&options, "open, closed, merged or all"),
+		Run: func(ctx context.Context, globals *GlobalOptions, args []string) error {
+			if err := exactArgs(args, 0); err != nil {
+				return err
+			}
+			if err := applyGlobalOptions(globals); err != nil {
+				return err
+			}
+			options.Hostname = globals.Hostname
+			options.RepoName = globals.Repo
+
+			pullRequests, err := gh_command.ListPullRequests(ctx, options)
+			if err != nil {
+				return err
+			}
+
+			tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			for _, pullRequest := range pullRequests {
+				state := pullRequest.State
+				if pullRequest.IsDraft {
+					state = "DRAFT"
+				}
+				fmt.Fprintf(
+					tw,
+					"#%d\t%s\t%s\t%s\t%s\n",
+					pullRequest.Number,
+					pullRequest.Title,
+					pullRequest.HeadRefName,
+					pullRequest.Author.Login,
+					state,
+				)
+			}
+
+			return tw.Flush()
+		},
+	}
+}
+
+// newPullRequestViewCommand function to build the "pr view" command
+func newPullRequestViewCommand() *Command {
+	return &Command{
+		Name:      "view",
+		ArgsUsage: "<number | url | branch>",
+		Summary:   "Show the detail of a pull request",
+		Run: func(ctx context.Context, globals *GlobalOptions, args []string) error {
+			if err := exactArgs(args, 1); err != nil {
+				return err
+			}
+			if err := applyGlobalOptions(globals); err != nil {
+				return err
+			}
+
+			pullRequest, err := gh_command.GetPullRequest(ctx, globals.Hostname, globals.Repo, args[0])
+			if err != nil {
+				return err
+			}
+
+			state := pullRequest.State
+			if pullRequest.IsDraft {
+				state = "DRAFT"
+			}
+			fmt.Printf("#%d %s\n", pullRequest.Number, pullRequest.Title)
+			fmt.Printf(
+				"%s • %s wants to merge %s into %s\n",
+				state,
+				pullRequest.Author.Login,
+				pullRequest.HeadRefName,
+				pullRequest.BaseRefName,
+			)
+			fmt.Println(pullRequest.URL)
+			if pullRequest.Body != "" {
+				fmt.Printf("\n%s\n", pullRequest.Body)
+			}
+
+			return nil
+		},
+	}
+}
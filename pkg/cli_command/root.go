@@ -0,0 +1,103 @@
+package cli_command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+
+	"github.com/charmbracelet/huh"
+	"github.com/coding-for-fun-org/lazygithub/pkg/cli_prompt"
+	"github.com/coding-for-fun-org/lazygithub/pkg/config"
+	"github.com/coding-for-fun-org/lazygithub/pkg/gh_api"
+	"github.com/coding-for-fun-org/lazygithub/pkg/gh_command"
+)
+
+// Exit codes of the lazygithub binary
+const (
+	ExitOK      = 0
+	ExitError   = 1
+	ExitUsage   = 2
+	ExitAborted = 130
+)
+
+// newRootCommand function to build the lazygithub command tree
+func newRootCommand() *Command {
+	prCreate := newPullRequestCreateCommand()
+
+	return &Command{
+		Name:    "lazygithub",
+		Summary: "Work with GitHub pull requests, issues and workflow runs from the terminal.\nWithout a command, \"pr create\" is run.",
+		Run: func(ctx context.Context, globals *GlobalOptions, args []string) error {
+			if len(args) > 0 {
+				return &usageError{message: fmt.Sprintf("unknown command %q for \"lazygithub\"", args[0])}
+			}
+
+			return prCreate.Run(ctx, globals, args)
+		},
+		Subcommands: []*Command{
+			newPullRequestCommand(prCreate),
+			newIssueCommand(),
+			newWorkflowRunCommand(),
+			newConfigCommand(),
+		},
+	}
+}
+
+// applyGlobalOptions function to apply the global flags to the environment of the commands that are run,
+// and to select the backend that talks to GitHub
+func applyGlobalOptions(globals *GlobalOptions) error {
+	if globals.Verbose {
+		os.Setenv("GH_DEBUG", "1")
+		log.SetFlags(log.Ltime | log.Lmicroseconds)
+	}
+
+	c, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	// gh reads the host to fall back on from GH_HOST, the flag wins over the environment which wins over the config
+	if globals.Hostname != "" {
+		os.Setenv("GH_HOST", globals.Hostname)
+	} else if os.Getenv("GH_HOST") == "" && c.Hostname != "" {
+		os.Setenv("GH_HOST", c.Hostname)
+	}
+
+	if c.Backend == config.BackendHTTP {
+		// The token is looked up per request since the host is only known once the repository is resolved
+		gh_command.SetBackend(gh_api.NewClient())
+	}
+
+	return nil
+}
+
+// Execute function to run the command selected by the arguments and return the exit code.
+// An interrupt cancels the command, stopping the gh and git processes it started.
+func Execute(args []string, stderr io.Writer) int {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	globals := &GlobalOptions{}
+	root := newRootCommand()
+
+	err := root.execute(ctx, []string{}, globals, args, stderr)
+
+	var usage *usageError
+	switch {
+	case err == nil, errors.Is(err, errHelp):
+		return ExitOK
+	case errors.As(err, &usage):
+		fmt.Fprintf(stderr, "\n%s\n", usage.message)
+		return ExitUsage
+	case errors.Is(err, cli_prompt.ErrAborted), errors.Is(err, huh.ErrUserAborted), errors.Is(err, context.Canceled):
+		fmt.Fprintln(stderr, "Aborted")
+		return ExitAborted
+	default:
+		fmt.Fprintln(stderr, describeError(err))
+		return ExitError
+	}
+}
@@ -0,0 +1,109 @@
+package cli_command
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/coding-for-fun-org/lazygithub/pkg/gh_command"
+)
+
+// newWorkflowRunCommand function to build the "run" command group
+func newWorkflowRunCommand() *Command {
+	return &Command{
+		Name:    "run",
+		Summary: "Work with GitHub Actions workflow runs",
+		Subcommands: []*Command{
+			newWorkflowRunListCommand(),
+			newWorkflowRunViewCommand(),
+		},
+	}
+}
+
+// workflowRunResult function to get the conclusion of a finished run, or its status otherwise
+func workflowRunResult(status string, conclusion string) string {
+	if conclusion != "" {
+		return conclusion
+	}
+
+	return status
+}
+
+// newWorkflowRunListCommand function to build the "run list" command
+func newWorkflowRunListCommand() *Command {
+	options := gh_command.ListOptions{}
+
+	return &Command{
+		Name:    "list",
+		Summary: "List the recent workflow runs of the repository",
+		Flags:   listFlags(&options, "queued, in_progress, completed, success, failure, ..."),
+		Run: func(ctx context.Context, globals *GlobalOptions, args []string) error {
+			if err := exactArgs(args, 0); err != nil {
+				return err
+			}
+			if err := applyGlobalOptions(globals); err != nil {
+				return err
+			}
+			options.Hostname = globals.Hostname
+			options.RepoName = globals.Repo
+
+			runs, err := gh_command.ListWorkflowRuns(ctx, options)
+			if err != nil {
+				return err
+			}
+
+			tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			for _, run := range runs {
+				fmt.Fprintf(
+					tw,
+					"%d\t%s\t%s\t%s\t%s\n",
+					run.DatabaseID,
+					workflowRunResult(run.Status, run.Conclusion),
+					run.WorkflowName,
+					run.DisplayTitle,
+					run.HeadBranch,
+				)
+			}
+
+			return tw.Flush()
+		},
+	}
+}
+
+// newWorkflowRunViewCommand function to build the "run view" command
+func newWorkflowRunViewCommand() *Command {
+	return &Command{
+		Name:      "view",
+		ArgsUsage: "<run-id>",
+		Summary:   "Show a workflow run and its jobs",
+		Run: func(ctx context.Context, globals *GlobalOptions, args []string) error {
+			if err := exactArgs(args, 1); err != nil {
+				return err
+			}
+			if err := applyGlobalOptions(globals); err != nil {
+				return err
+			}
+
+			run, err := gh_command.GetWorkflowRun(ctx, globals.Hostname, globals.Repo, args[0])
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("%s • %s\n", run.WorkflowName, run.DisplayTitle)
+			fmt.Printf("%s on %s\n", workflowRunResult(run.Status, run.Conclusion), run.HeadBranch)
+			fmt.Println(run.URL)
+
+			if len(run.Jobs) > 0 {
+				fmt.Println("\nJobs:")
+				tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+				for _, job := range run.Jobs {
+					fmt.Fprintf(tw, "  %s\t%s\n", job.Name, workflowRunResult(job.Status, job.Conclusion))
+				}
+				return tw.Flush()
+			}
+
+			return nil
+		},
+	}
+}
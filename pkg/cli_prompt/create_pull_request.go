@@ -1,172 +1,653 @@
 package cli_prompt
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
-	"sort"
+	"os"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/huh"
-	"github.com/charmbracelet/huh/spinner"
+	"github.com/coding-for-fun-org/lazygithub/pkg/codeowners"
+	"github.com/coding-for-fun-org/lazygithub/pkg/config"
 	"github.com/coding-for-fun-org/lazygithub/pkg/gh_command"
 	"github.com/coding-for-fun-org/lazygithub/pkg/git_command"
+	"github.com/coding-for-fun-org/lazygithub/pkg/state"
+	"golang.org/x/sync/errgroup"
 )
 
+// ErrAborted is returned when the user stops one of the prompts
+var ErrAborted = errors.New("aborted")
+
+// CreatePullRequestOptions struct to represent the values given from the command line.
+// Any value that is left empty is asked for interactively.
+type CreatePullRequestOptions struct {
+	// Repo is "[host/]owner/name", or "" for the repository of the current directory
+	Repo string
+	// Hostname is the GitHub host, the git remotes decide it when empty
+	Hostname string
+	Head     string
+	Base     string
+	Title    string
+	Body     string
+	BodyFile string
+	// Reviewers is nil when no reviewers were given, and an empty slice when
+	// the user explicitly asked for no reviewers.
+	Reviewers []string
+	// Draft is nil when the draft state was not given.
+	Draft *bool
+	// Push is nil when it was not given whether the head branch may be pushed before creating the pull request.
+	Push *bool
+	// DryRun goes through the whole flow but prints the pull request as JSON instead of sending it to GitHub,
+	// leaving the remote and the saved choices alone.
+	DryRun bool
+	// Editor opens the body in $VISUAL or $EDITOR instead of asking for it in the form.
+	Editor bool
+	// MaintainerCanModify is nil when it was not given whether the maintainers may push to the head branch of a fork.
+	MaintainerCanModify *bool
+	// Template is the path of the pull request template relative to the repository root, "none" uses no template
+	Template string
+	// Yes accepts the prepopulated or default value for everything that was not given.
+	Yes bool
+}
+
 type CreatePullRequest struct {
+	options         CreatePullRequestOptions
+	config          config.Config
 	repoId          string
+	repoHost        string
 	repoOwner       string
 	repoName        string
+	myUserLogin     string
+	reviewerRanks   []state.ReviewerRank
+	latestReviewers []string
+	codeOwners      []string
+	// codeOwnerTeams are "org/team" slugs
+	codeOwnerTeams  []string
 	assignableUsers []gh_command.RepoAssignableUser
+	teams           []gh_command.RepoTeam
+	repoLabels      []gh_command.RepoLabel
+	repoMilestones  []gh_command.RepoMilestone
+	repoProjects    []gh_command.RepoProject
+	latestMetadata  *state.MetadataSelection
 	defaultBranch   string
 	latestBranches  []git_command.ListLatestBranchesResponse
+	templates       []pullRequestTemplate
+	templatePath    string
 	baseBranch      string
 	headBranch      string
 	title           string
 	body            string
 	reviewers       []string
-	isDraft         bool
+	// teamReviewers are "org/team" slugs
+	teamReviewers []string
+	// labels, assignees, milestone and projects are names, logins and titles, resolved into IDs when submitting
+	labels    []string
+	assignees []string
+	milestone string
+	projects  []string
+	isDraft   bool
+	// headRepoOwner and headRepoName are the fork of the head branch, empty when it is in the base repository
+	headRepoOwner string
+	headRepoName  string
+	// maintainerCanModify is only sent when the head branch is in a fork
+	maintainerCanModify bool
+	// existing is the open pull request of the head branch, which is edited instead of creating another one
+	existing *gh_command.PullRequestDetail
+	// titleCandidates are the suggested titles, the first one is prefilled
+	titleCandidates []string
+	// revising is set when the user goes back from the preview, the title and body are asked again even when they were given
+	revising bool
+	// pullRequest is the pull request created or edited by Run, with the submitted title and body
+	pullRequest gh_command.PullRequest
+}
+
+// NewCreatePullRequest function to create a pull request prompt prefilled with the given options
+func NewCreatePullRequest(options CreatePullRequestOptions) *CreatePullRequest {
+	// Like on GitHub, maintainers may push to the head branch of a fork unless the user says otherwise
+	return &CreatePullRequest{options: options, maintainerCanModify: true}
 }
 
-// initializeBaseInfo method to initialize the base information for creating a pull request
-func (p *CreatePullRequest) initializeBaseInfo() {
-	r := gh_command.Repo{RepoName: ""}
-	repo := r.Get(gh_command.GetRepoOptions{})
+// initializeBaseInfo method to initialize the base information for creating a pull request.
+// The repository, the current user, the local branches, the templates and the saved reviewers
+// are loaded at the same time, the first failure cancels the other loads.
+func (p *CreatePullRequest) initializeBaseInfo(ctx context.Context) error {
+	ref, err := gh_command.ResolveRepo(ctx, p.options.Repo, p.options.Hostname)
+	if err != nil {
+		return fmt.Errorf("failed to load the repository: %w", err)
+	}
+
+	g, ctx := errgroup.WithContext(ctx)
+
+	g.Go(func() error {
+		r := gh_command.Repo{Hostname: ref.Host, RepoName: ref.Owner + "/" + ref.Name}
+		repo, err := r.Get(ctx, gh_command.GetRepoOptions{Teams: p.needsTeams(), Metadata: p.promptsMetadata()})
+		if err != nil {
+			return fmt.Errorf("failed to load the repository: %w", err)
+		}
+
+		p.repoId = repo.ID
+		p.repoHost = repo.Host
+		p.repoOwner = repo.Owner.Login
+		p.repoName = repo.Name
+		p.assignableUsers = repo.AssignableUsers
+		p.teams = repo.Teams
+		p.repoLabels = repo.Labels
+		p.repoMilestones = repo.Milestones
+		p.repoProjects = repo.Projects
+		p.defaultBranch = repo.DefaultBranchRef.Name
+		p.initializeHeadRepository(ctx)
+
+		if p.options.Reviewers == nil || p.promptsMetadata() {
+			p.initializeHistory()
+		}
+
+		return nil
+	})
+
+	if p.promptsReviewers() || p.promptsMetadata() {
+		g.Go(func() error {
+			myUserLogin, err := gh_command.GetMyUserLogin(ctx, ref.Host)
+			if err != nil && ctx.Err() == nil {
+				// When the login can not be loaded, nobody is filtered out rather than failing the form
+				log.Printf("Failed to load the current user: %s", err)
+			}
+			p.myUserLogin = myUserLogin
+
+			return ctx.Err()
+		})
+	}
+
+	g.Go(func() error {
+		latestBranches, err := git_command.ListLatestBranches(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list the local branches: %w", err)
+		}
+		p.latestBranches = latestBranches
+
+		return nil
+	})
+
+	if p.config.PullRequestTemplateFor(ref.Owner, ref.Name).IsEnabled() {
+		g.Go(func() error {
+			templates, err := p.loadPullRequestTemplates(ctx)
+			if err != nil && ctx.Err() == nil {
+				log.Printf("Failed to load the pull request templates: %s", err)
+			}
+			p.templates = templates
+
+			return ctx.Err()
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return err
+	}
+	if p.options.Reviewers == nil {
+		p.preselectReviewers()
+	}
+	if p.promptsMetadata() {
+		p.preselectMetadata()
+	}
 
-	p.repoId = repo.ID
-	p.repoOwner = repo.Owner.Login
-	p.repoName = repo.Name
-	p.assignableUsers = repo.AssignableUsers
-	p.defaultBranch = repo.DefaultBranchRef.Name
-	p.latestBranches = git_command.ListLatestBranches()
+	return nil
 }
 
-// branchForm method to create a form for selecting the base and head branches
-func (p *CreatePullRequest) branchForm() *huh.Form {
-	branchForm := huh.NewForm(
+// loadPullRequestTemplates method to find the pull request templates in the working tree
+func (p *CreatePullRequest) loadPullRequestTemplates(ctx context.Context) ([]pullRequestTemplate, error) {
+	root, err := git_command.GetRepositoryRoot(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return findPullRequestTemplates(root)
+}
+
+// selectedTemplate method to get the pull request template that was chosen, nil when there is none
+func (p *CreatePullRequest) selectedTemplate() *pullRequestTemplate {
+	for i := range p.templates {
+		if p.templates[i].path == p.templatePath {
+			return &p.templates[i]
+		}
+	}
+
+	return nil
+}
+
+// templateForm method to create a form for choosing one of several pull request templates.
+// It returns nil when there is nothing to choose from.
+func (p *CreatePullRequest) templateForm() *huh.Form {
+	if len(p.templates) < 2 || !p.promptsBody() || p.options.Template != "" || p.existing != nil {
+		return nil
+	}
+
+	options := make([]huh.Option[string], 0, len(p.templates)+1)
+	for _, template := range p.templates {
+		options = append(options, huh.NewOption(template.path, template.path))
+	}
+	options = append(options, huh.NewOption("No template", ""))
+
+	return huh.NewForm(
 		huh.NewGroup(
 			huh.NewSelect[string]().
-				Title("Select the head branch").
-				Options((func() []huh.Option[string] {
-					// filter out the default branch
-					branches := make([]huh.Option[string], 0)
-					for _, branch := range p.latestBranches {
-						if branch.Ref != p.defaultBranch {
-							branches = append(
-								branches,
-								huh.NewOption(branch.Ref, branch.Ref),
-							)
-						}
-					}
-
-					return branches
-				})()...).
-				Value(&p.headBranch),
-
-			// I'd like to put this in another group but
-			// because of the current bug, I can not do it.
-			// https://github.com/charmbracelet/huh/issues/419
-			huh.NewSelect[string]().
-				Title("Select the base branch").
-				OptionsFunc(func() []huh.Option[string] {
-					// filter out the default branch
-					branches := make([]huh.Option[string], 0)
-					branches = append(
-						branches,
-						huh.NewOption(p.defaultBranch, p.defaultBranch),
-					)
-					for _, branch := range p.latestBranches {
-						if branch.Ref != p.defaultBranch && branch.Ref != p.headBranch {
-							branches = append(
-								branches,
-								huh.NewOption(branch.Ref, branch.Ref),
-							)
-						}
-					}
-
-					return branches
-				}, &p.headBranch).
-				Value(&p.baseBranch),
+				Title("Select the pull request template").
+				Options(options...).
+				Value(&p.templatePath),
 		),
 	)
+}
+
+// headBranchForm method to create a form for selecting the head branch.
+// It returns nil when the head branch was given from the command line.
+func (p *CreatePullRequest) headBranchForm() *huh.Form {
+	if !p.promptsHeadBranch() {
+		return nil
+	}
+
+	// filter out the default branch
+	branches := make([]huh.Option[string], 0)
+	for _, branch := range p.latestBranches {
+		if branch.Ref != p.defaultBranch {
+			label := branch.Ref
+			if p.isFork() {
+				label = p.headRepoOwner + ":" + branch.Ref
+			}
+			branches = append(
+				branches,
+				huh.NewOption(label, branch.Ref),
+			)
+		}
+	}
+
+	return huh.NewForm(huh.NewGroup(
+		huh.NewSelect[string]().
+			Title("Select the head branch").
+			Options(branches...).
+			Value(&p.headBranch),
+	))
+}
+
+// baseBranchForm method to create a form for selecting the base branch.
+// It is asked for once the open pull request of the head branch is known, so that its base is preselected,
+// and it returns nil when the base branch was given from the command line.
+func (p *CreatePullRequest) baseBranchForm() *huh.Form {
+	if !p.promptsBaseBranch() {
+		return nil
+	}
 
-	return branchForm
+	// the default branch comes first, then the base of the open pull request, which may be no local branch
+	branches := []huh.Option[string]{huh.NewOption(p.defaultBranch, p.defaultBranch)}
+	if p.existing != nil && p.existing.BaseRefName != p.defaultBranch {
+		branches = append(branches, huh.NewOption(p.existing.BaseRefName, p.existing.BaseRefName))
+	}
+	for _, branch := range p.latestBranches {
+		if branch.Ref != p.defaultBranch && branch.Ref != p.headBranch &&
+			(p.existing == nil || branch.Ref != p.existing.BaseRefName) {
+			branches = append(
+				branches,
+				huh.NewOption(branch.Ref, branch.Ref),
+			)
+		}
+	}
+
+	return huh.NewForm(huh.NewGroup(
+		huh.NewSelect[string]().
+			Title("Select the base branch").
+			Options(branches...).
+			Value(&p.baseBranch),
+	))
+}
+
+// initializeBranchInfo method to load what depends on the chosen branches, the title, the body and the code owners
+func (p *CreatePullRequest) initializeBranchInfo(ctx context.Context) error {
+	g, ctx := errgroup.WithContext(ctx)
+
+	if p.existing == nil {
+		g.Go(func() error {
+			return p.initializePullRequestTitleAndBody(ctx)
+		})
+	}
+
+	if p.options.Reviewers == nil {
+		g.Go(func() error {
+			if err := p.initializeCodeOwners(ctx); err != nil && ctx.Err() == nil {
+				// The code owners are only suggestions, so the pull request can still be created without them
+				log.Printf("Failed to load the code owners: %s", err)
+			}
+
+			return ctx.Err()
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return err
+	}
+	if p.options.Reviewers == nil && p.config.Reviewers.PreselectCodeOwners && p.existing == nil {
+		p.reviewers = concatenateAndRemoveDuplicates(
+			p.reviewers,
+			requestableReviewers(userCandidates(p.assignableUsers, p.myUserLogin), p.codeOwners),
+		)
+		p.teamReviewers = concatenateAndRemoveDuplicates(
+			p.teamReviewers,
+			requestableReviewers(teamCandidates(p.teams, p.repoOwner), p.codeOwnerTeams),
+		)
+	}
+
+	return nil
+}
+
+// initializeCodeOwners method to find the users and teams owning the files changed between the branches
+func (p *CreatePullRequest) initializeCodeOwners(ctx context.Context) error {
+	root, err := git_command.GetRepositoryRoot(ctx)
+	if err != nil {
+		return err
+	}
+	ruleset, path, err := codeowners.Find(root)
+	if err != nil || path == "" {
+		return err
+	}
+	for _, lineErr := range ruleset.Errors {
+		log.Printf("Skipped an invalid line of %s: %s", path, lineErr)
+	}
+
+	files, err := git_command.ListChangedFiles(ctx, p.baseBranch, p.headBranch)
+	if err != nil {
+		return err
+	}
+
+	for _, owner := range ruleset.OwnersOf(files) {
+		// Email addresses can not be mapped to a login, so only the "@" owners are kept
+		if !strings.HasPrefix(owner, "@") {
+			continue
+		}
+		if strings.Contains(owner, "/") {
+			p.codeOwnerTeams = append(p.codeOwnerTeams, strings.TrimPrefix(owner, "@"))
+		} else {
+			p.codeOwners = append(p.codeOwners, strings.TrimPrefix(owner, "@"))
+		}
+	}
+
+	return nil
+}
+
+// localBaseRef method to get the ref the head branch is compared with in the local repository,
+// false when the head branch or the base branch is not available locally.
+// The base branch is compared through the remote tracking branch of the repository the pull request is opened
+// against, the upstream remote of a fork, which is what GitHub had at the last fetch.
+// The local base branch is only used when that was never fetched.
+func (p *CreatePullRequest) localBaseRef(ctx context.Context) (string, bool, error) {
+	_, headIsLocal, err := git_command.GetBranchStatus(ctx, p.headBranch)
+	if err != nil {
+		return "", false, err
+	}
+	if !headIsLocal {
+		return "", false, nil
+	}
+
+	remote, ok, err := p.repositoryRemote(ctx, p.repoOwner, p.repoName)
+	if err != nil {
+		return "", false, err
+	}
+	if ok {
+		remoteRef := "refs/remotes/" + remote + "/" + p.baseBranch
+		fetched, err := git_command.RefExists(ctx, remoteRef)
+		if err != nil {
+			return "", false, err
+		}
+		if fetched {
+			return remoteRef, true, nil
+		}
+	}
+
+	_, baseIsLocal, err := git_command.GetBranchStatus(ctx, p.baseBranch)
+	if err != nil {
+		return "", false, err
+	}
+	if !baseIsLocal {
+		return "", false, nil
+	}
+
+	return p.baseBranch, true, nil
+}
+
+// loadCommits method to list the commits of the pull request from the local repository,
+// or from GitHub when one of the branches only exists on the remote
+func (p *CreatePullRequest) loadCommits(ctx context.Context) ([]gh_command.Commit, error) {
+	baseRef, ok, err := p.localBaseRef(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return gh_command.GetBranchCommits(ctx, p.repoHost, p.repoOwner, p.repoName, p.baseBranch, p.headRef())
+	}
+
+	return git_command.ListCommits(ctx, baseRef, p.headBranch)
 }
 
 // initializePullRequestTitleAndBody method to initialize the pull request title and body
-func (p *CreatePullRequest) initializePullRequestTitleAndBody() {
-	commits := gh_command.GetBranchCommits(p.repoOwner, p.repoName, p.baseBranch, p.headBranch)
+func (p *CreatePullRequest) initializePullRequestTitleAndBody(ctx context.Context) error {
+	commits, err := p.loadCommits(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load the commits between %s and %s: %w", p.baseBranch, p.headBranch, err)
+	}
 
-	title, body := getPrePopulatedTitleAndBody(commits)
-	p.title = title
-	p.body = body
+	rules := p.config.IssueTrackersFor(p.repoHost, p.repoOwner, p.repoName)
+	tmpl, err := p.bodyTemplate(ctx)
+	if err != nil {
+		return err
+	}
+	data := newBodyData(commits, rules, p.headBranch, p.baseBranch, p.bodyDiffStats(ctx))
+
+	title := ""
+	if template := p.selectedTemplate(); template == nil {
+		title, p.body, err = getPrePopulatedTitleAndBody(tmpl, data, rules)
+		if err != nil {
+			return err
+		}
+	} else {
+		var commitBody, linkBody string
+		title, commitBody, linkBody, err = getPrePopulatedContent(tmpl, data, rules)
+		if err != nil {
+			return err
+		}
+		p.body = applyPullRequestTemplate(
+			template.content,
+			commitBody,
+			linkBody,
+			p.config.PullRequestTemplateFor(p.repoOwner, p.repoName).Heading,
+		)
+	}
+
+	p.titleCandidates = titleCandidates(title, commits, p.headBranch, rules, p.config.Title.IsBranchFallbackEnabled())
+	p.title = ""
+	if len(p.titleCandidates) > 0 {
+		p.title = p.titleCandidates[0]
+	}
+
+	return nil
+}
+
+// initializeHistory method to load the reviewers and the metadata chosen before in the repository
+func (p *CreatePullRequest) initializeHistory() {
+	store, err := state.NewStore()
+	if err != nil {
+		log.Printf("Failed to open the state file: %s", err)
+		return
+	}
+	s, err := store.Load()
+	if err != nil {
+		log.Printf("Failed to load the latest choices: %s", err)
+		return
+	}
+	repository, ok := s.Repositories[p.repoId]
+	if !ok {
+		return
+	}
+	p.reviewerRanks = repository.RankReviewers(time.Now(), p.config.Reviewers.HalfLife())
+	p.latestReviewers = repository.LatestReviewers()
+	p.latestMetadata = repository.LatestMetadata
+}
+
+// preselectReviewers method to preselect the reviewers the way the config asks for
+func (p *CreatePullRequest) preselectReviewers() {
+	p.reviewers = []string{}
+	p.teamReviewers = []string{}
+
+	switch p.config.Reviewers.PreselectMode() {
+	case config.PreselectLatest:
+		if p.latestReviewers != nil {
+			p.reviewers, p.teamReviewers = splitTeamReviewers(p.latestReviewers)
+		}
+	case config.PreselectSuggested:
+		p.reviewers, p.teamReviewers = splitTeamReviewers(p.suggestedReviewers())
+	}
+}
+
+// suggestedReviewers method to get the top ranked users and teams that can be requested on the repository
+func (p *CreatePullRequest) suggestedReviewers() []string {
+	candidates := append(userCandidates(p.assignableUsers, p.myUserLogin), teamCandidates(p.teams, p.repoOwner)...)
+
+	return suggestedReviewers(candidates, p.reviewerRanks, p.config.Reviewers.SuggestionCount())
 }
 
-// initializeReviewers method to initialize the reviewers
-func (p *CreatePullRequest) initializeReviewers() {
-	savedReviewers, err := getLatestReviewers(p.repoId)
+// saveChoices method to add the chosen reviewers to the history of the repository
+// and remember the chosen metadata when it was asked for
+func (p *CreatePullRequest) saveChoices() error {
+	store, err := state.NewStore()
 	if err != nil {
-		savedReviewers = []string{}
+		return err
 	}
-	p.reviewers = savedReviewers
+
+	return store.Update(func(s *state.State) error {
+		repository := s.Repository(p.repoId)
+		repository.Name = p.repoHost + "/" + p.repoOwner + "/" + p.repoName
+		repository.AddReviewerSelection(concatenateAndRemoveDuplicates(p.reviewers, p.teamReviewers), time.Now())
+		if p.promptsMetadata() {
+			repository.LatestMetadata = &state.MetadataSelection{
+				Labels:    p.labels,
+				Assignees: p.assignees,
+				Milestone: p.milestone,
+				Projects:  p.projects,
+			}
+		}
+
+		return nil
+	})
 }
 
+// runRestForm method to ask for the title, body, reviewers and draft state that are still missing,
+// in the editor for the body when --editor was given
+func (p *CreatePullRequest) runRestForm(ctx context.Context, interactive bool) error {
+	if p.options.Editor && (p.promptsBody() || p.revising) {
+		if err := p.editBody(ctx); err != nil {
+			return err
+		}
+	}
+
+	restForm := p.restForm()
+	if restForm == nil {
+		return nil
+	}
+
+	// The title is the only value that can still be missing here, when it could not be derived from the commits
+	if !interactive {
+		return missingOptionsError([]string{"title"})
+	}
+
+	errRestForm := restForm.Run()
+	if errRestForm != nil {
+		return errRestForm
+	}
+
+	// If the user stops the program, we don't want to go to the next form
+	if restForm.State == huh.StateAborted {
+		return ErrAborted
+	}
+
+	return nil
+}
+
+// editBody method to let the user write the prepopulated body in their editor
+func (p *CreatePullRequest) editBody(ctx context.Context) error {
+	body, err := editInEditor(ctx, p.body, ".md", []string{
+		fmt.Sprintf("Write the body of the pull request from %s into %s.", p.headRef(), p.baseBranch),
+		fmt.Sprintf("Lines starting with %q are removed.", editorCommentPrefix),
+	})
+	if err != nil {
+		return err
+	}
+	p.body = body
+
+	return nil
+}
+
+// restForm method to create a form for the title, body, reviewers and draft state.
+// It returns nil when every value was given from the command line.
 func (p *CreatePullRequest) restForm() *huh.Form {
-	restForm := huh.NewForm(
-		huh.NewGroup(
+	fields := make([]huh.Field, 0)
+
+	if p.promptsTitle() || p.title == "" || p.revising {
+		fields = append(
+			fields,
 			huh.NewInput().
 				Title("Enter the pull request title").
 				Value(&p.title),
+		)
+	}
 
+	if (p.promptsBody() || p.revising) && !p.options.Editor {
+		fields = append(
+			fields,
 			huh.NewText().
 				Title("Enter the pull request body").
+				// ctrl+e opens the body in the editor, the extension lets it highlight the markdown
+				Editor(editorCommand()...).
+				EditorExtension(".md").
 				ShowLineNumbers(true).
 				Value(&p.body).
 				// If I pass 0 to WithCharLimit, it will not limit the number of characters.
 				CharLimit(0).
 				// Calculate the line count of current text and add 5 to it as the height of the text box.
 				WithHeight(strings.Count(p.body, "\n")+5),
+		)
+	}
 
+	if p.promptsReviewers() {
+		suggested := p.suggestedReviewers()
+		fields = append(
+			fields,
 			huh.NewMultiSelect[string]().
 				Title("Select reviewers").
-				Options((func() []huh.Option[string] {
-					myUserLogin := gh_command.GetMyUserLogin()
-					users := make([]huh.Option[string], 0)
-					userLoginMap := make(map[string]string)
-
-					// Step 1: Map each login to the corresponding name
-					for _, user := range p.assignableUsers {
-						if user.Login != myUserLogin {
-							userLoginMap[user.Login] = user.Name
-						}
-					}
-
-					// Step 2: Collect and sort the logins
-					sortedLogins := make([]string, 0, len(userLoginMap))
-					for login := range userLoginMap {
-						sortedLogins = append(sortedLogins, login)
-					}
-					sort.Strings(sortedLogins)
-
-					// Step 3: Construct `users` in alphabetical order
-					for _, login := range sortedLogins {
-						name := userLoginMap[login]
-
-						format := "%s"
-						if name != "" {
-							format += " (%s)"
-						}
-						spread := []interface{}{login}
-						if name != "" {
-							spread = append(spread, name)
-						}
-
-						users = append(users, huh.NewOption(fmt.Sprintf(format, spread...), login))
-					}
-
-					return users
-				})()...).
+				Options(reviewerOptions(
+					userCandidates(p.assignableUsers, p.myUserLogin),
+					p.reviewerRanks,
+					suggested,
+					p.codeOwners,
+				)...).
 				Value(&p.reviewers),
+		)
+
+		if len(p.teams) > 0 {
+			fields = append(
+				fields,
+				huh.NewMultiSelect[string]().
+					Title("Select team reviewers").
+					Options(reviewerOptions(
+						teamCandidates(p.teams, p.repoOwner),
+						p.reviewerRanks,
+						suggested,
+						p.codeOwnerTeams,
+					)...).
+					Value(&p.teamReviewers),
+			)
+		}
+	}
 
+	if p.promptsDraft() {
+		fields = append(
+			fields,
 			huh.NewSelect[bool]().
 				Title("Are you going to create a draft PR?").
 				Options(
@@ -174,78 +655,262 @@ func (p *CreatePullRequest) restForm() *huh.Form {
 					huh.NewOption("No", false),
 				).
 				Value(&p.isDraft),
-		),
-	)
+		)
+	}
+
+	if p.promptsMaintainerCanModify() {
+		fields = append(
+			fields,
+			huh.NewSelect[bool]().
+				Title(fmt.Sprintf("Allow the maintainers of %s/%s to push to %s?", p.repoOwner, p.repoName, p.headRef())).
+				Options(
+					huh.NewOption("Yes", true),
+					huh.NewOption("No", false),
+				).
+				Value(&p.maintainerCanModify),
+		)
+	}
 
-	return restForm
+	groups := make([]*huh.Group, 0, 2)
+	if len(fields) > 0 {
+		groups = append(groups, huh.NewGroup(fields...))
+	}
+	if metadataGroup := p.metadataGroup(); metadataGroup != nil {
+		groups = append(groups, metadataGroup)
+	}
+	if len(groups) == 0 {
+		return nil
+	}
+
+	return huh.NewForm(groups...)
 }
 
-// Run method to run the create pull request prompt
-func (p *CreatePullRequest) Run() {
-	initializeBaseInfo := p.initializeBaseInfo
-	spinner.New().
-		Title("Loading base information to create a pull request...").
-		Action(initializeBaseInfo).
-		Run()
+// reviewerIDs method to resolve reviewer logins into user node IDs
+func (p *CreatePullRequest) reviewerIDs(reviewers []string) []string {
+	idByLogin := make(map[string]string)
+	for _, user := range p.assignableUsers {
+		idByLogin[user.Login] = user.ID
+	}
 
-	branchForm := p.branchForm()
-	errBranchForm := branchForm.Run()
-	if errBranchForm != nil {
-		log.Fatal(errBranchForm)
+	ids := make([]string, 0, len(reviewers))
+	for _, reviewer := range reviewers {
+		if id, ok := idByLogin[reviewer]; ok {
+			ids = append(ids, id)
+		}
 	}
 
-	// If the user stops the program, we don't want to go to the next form
-	if branchForm.State == huh.StateAborted {
-		fmt.Println("Aborted")
-		return
+	return ids
+}
+
+// teamReviewerIDs method to resolve "org/team" slugs into team node IDs
+func (p *CreatePullRequest) teamReviewerIDs(teamReviewers []string) []string {
+	idBySlug := make(map[string]string)
+	for _, team := range p.teams {
+		idBySlug[p.repoOwner+"/"+team.Slug] = team.ID
 	}
 
-	initializePullRequestTitleAndBody := p.initializePullRequestTitleAndBody
+	ids := make([]string, 0, len(teamReviewers))
+	for _, reviewer := range teamReviewers {
+		if id, ok := idBySlug[reviewer]; ok {
+			ids = append(ids, id)
+		}
+	}
 
-	spinner.New().
-		Title("Loading title and body").
-		Action(initializePullRequestTitleAndBody).
-		Run()
+	return ids
+}
 
-	initializeReviewers := p.initializeReviewers
+// submit method to create the pull request on GitHub and request the selected reviewers
+func (p *CreatePullRequest) submit(ctx context.Context) (gh_command.PullRequest, error) {
+	options := gh_command.CreatePullRequestOptions{
+		Hostname:   p.repoHost,
+		RepoID:     p.repoId,
+		BaseBranch: p.baseBranch,
+		HeadBranch: p.headRef(),
+		Title:      p.title,
+		Body:       p.body,
+		IsDraft:    p.isDraft,
+	}
+	if p.isFork() {
+		options.MaintainerCanModify = &p.maintainerCanModify
+	}
+	pullRequest, err := gh_command.CreatePullRequest(ctx, options)
+	if err != nil {
+		return gh_command.PullRequest{}, fmt.Errorf("failed to create pull request: %w", err)
+	}
 
-	spinner.New().
-		Title("Loading latest reviewers").
-		Action(initializeReviewers).
-		Run()
+	err = gh_command.RequestReviews(ctx, p.repoHost, pullRequest.ID, p.reviewerIDs(p.reviewers), p.teamReviewerIDs(p.teamReviewers))
+	if err != nil {
+		return pullRequest, fmt.Errorf(
+			"pull request #%d was created at %s but requesting reviewers failed: %w",
+			pullRequest.Number,
+			pullRequest.URL,
+			err,
+		)
+	}
 
-	restForm := p.restForm()
-	errRestForm := restForm.Run()
-	if errRestForm != nil {
-		log.Fatal(errRestForm)
+	if err := p.submitMetadata(ctx, pullRequest.ID); err != nil {
+		return pullRequest, fmt.Errorf(
+			"pull request #%d was created at %s but %w",
+			pullRequest.Number,
+			pullRequest.URL,
+			err,
+		)
 	}
 
-	// If the user stops the program, we don't want to go to the next form
-	if restForm.State == huh.StateAborted {
-		fmt.Println("Aborted")
-		return
+	return pullRequest, nil
+}
+
+// Run method to run the create pull request prompt, the loads and the requests stop when the context is done
+func (p *CreatePullRequest) Run(ctx context.Context) error {
+	interactive := isInteractive()
+	if !interactive {
+		if missing := p.missingOptions(); len(missing) > 0 {
+			return missingOptionsError(missing)
+		}
 	}
 
-	fmt.Println("===REPO OWNER===")
-	fmt.Println(p.repoOwner)
-	fmt.Println("===REPO NAME===")
-	fmt.Println(p.repoName)
+	c, err := config.Load()
+	if err != nil {
+		return err
+	}
+	p.config = c
 
-	fmt.Println("===HEAD BRANCH===")
-	fmt.Println(p.headBranch)
-	fmt.Println("===BASE BRANCH===")
-	fmt.Println(p.baseBranch)
+	if err := p.chooseTargetRepository(ctx, interactive); err != nil {
+		return err
+	}
 
-	fmt.Println("===TITLE===")
-	fmt.Println(p.title)
-	fmt.Println("===BODY===")
-	fmt.Println(p.body)
-	fmt.Println("===REVIEWERS===")
-	err := writeLatestReviewers(p.repoId, p.reviewers)
+	err = runWithSpinner(ctx, "Loading base information to create a pull request...", p.initializeBaseInfo)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
-	for _, reviewer := range p.reviewers {
-		fmt.Println(reviewer)
+
+	p.applyBranchOptions()
+
+	if headBranchForm := p.headBranchForm(); headBranchForm != nil {
+		errHeadBranchForm := headBranchForm.Run()
+		if errHeadBranchForm != nil {
+			return errHeadBranchForm
+		}
+
+		// If the user stops the program, we don't want to go to the next form
+		if headBranchForm.State == huh.StateAborted {
+			return ErrAborted
+		}
 	}
+
+	err = runWithSpinner(ctx, "Looking for an open pull request", p.initializeExistingPullRequest)
+	if err != nil {
+		return err
+	}
+
+	p.initializeBaseBranch()
+
+	if baseBranchForm := p.baseBranchForm(); baseBranchForm != nil {
+		errBaseBranchForm := baseBranchForm.Run()
+		if errBaseBranchForm != nil {
+			return errBaseBranchForm
+		}
+
+		// If the user stops the program, we don't want to go to the next form
+		if baseBranchForm.State == huh.StateAborted {
+			return ErrAborted
+		}
+	}
+
+	if err := p.applyTemplateOptions(); err != nil {
+		return err
+	}
+
+	if templateForm := p.templateForm(); templateForm != nil {
+		errTemplateForm := templateForm.Run()
+		if errTemplateForm != nil {
+			return errTemplateForm
+		}
+
+		// If the user stops the program, we don't want to go to the next form
+		if templateForm.State == huh.StateAborted {
+			return ErrAborted
+		}
+	}
+
+	// The commits are read from GitHub when the base branch is not available locally,
+	// which only sees the head branch once it was pushed, and the push question is better asked before the spinner
+	if err := p.pushHeadBranch(ctx, interactive); err != nil {
+		return err
+	}
+
+	err = runWithSpinner(ctx, "Loading title, body and code owners", p.initializeBranchInfo)
+	if err != nil {
+		return err
+	}
+
+	if err := p.applyRestOptions(); err != nil {
+		return err
+	}
+
+	if titleForm := p.titleForm(); titleForm != nil && interactive {
+		errTitleForm := titleForm.Run()
+		if errTitleForm != nil {
+			return errTitleForm
+		}
+
+		// If the user stops the program, we don't want to go to the next form
+		if titleForm.State == huh.StateAborted {
+			return ErrAborted
+		}
+	}
+
+	for {
+		if err := p.runRestForm(ctx, interactive); err != nil {
+			return err
+		}
+
+		// Nobody is there to look at the preview without a terminal or with --yes, and a dry run prints it instead
+		if !interactive || p.options.Yes || p.options.DryRun {
+			break
+		}
+		action, err := p.confirmPreview(ctx)
+		if err != nil {
+			return err
+		}
+		if action == previewCancel {
+			return ErrAborted
+		}
+		if action == previewSubmit {
+			break
+		}
+		p.revising = true
+	}
+
+	if p.options.DryRun {
+		return p.printDryRun(os.Stdout)
+	}
+
+	spinnerTitle, submit, done := "Creating the pull request", p.submit, "Created"
+	if p.existing != nil {
+		spinnerTitle, submit, done = "Updating the pull request", p.submitEdit, "Updated"
+	}
+
+	var pullRequest gh_command.PullRequest
+	err = runWithSpinner(ctx, spinnerTitle, func(ctx context.Context) error {
+		var errSubmit error
+		pullRequest, errSubmit = submit(ctx)
+		return errSubmit
+	})
+	if err != nil {
+		return err
+	}
+	pullRequest.Title, pullRequest.Body = p.title, p.body
+	pullRequest.HeadRefName, pullRequest.BaseRefName = p.headBranch, p.baseBranch
+	p.pullRequest = pullRequest
+
+	err = p.saveChoices()
+	if err != nil {
+		log.Printf("Failed to save the latest choices: %s", err)
+	}
+
+	fmt.Printf("%s pull request #%d\n", done, pullRequest.Number)
+	fmt.Println(pullRequest.URL)
+
+	return nil
 }
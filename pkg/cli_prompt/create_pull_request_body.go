@@ -0,0 +1,284 @@
+package cli_prompt
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/coding-for-fun-org/lazygithub/pkg/config"
+	"github.com/coding-for-fun-org/lazygithub/pkg/gh_command"
+	"github.com/coding-for-fun-org/lazygithub/pkg/git_command"
+)
+
+// bodyPresets are the built-in templates of the pull request body, keyed by the name the config uses
+var bodyPresets = map[string]string{
+	// A single commit already gives its summary to the title, so only the rest of its message is kept
+	config.BodyPresetFull: `{{if eq (len .Commits) 1}}{{(index .Commits 0).Body}}{{else}}{{range .Commits}}{{.Summary}}
+
+{{.Body}}
+---
+{{end}}{{end}}`,
+	config.BodyPresetChangelog: `{{range .Commits}}- {{.Summary}}
+{{end}}`,
+	config.BodyPresetGrouped: `{{range $i, $group := .Groups}}{{if $i}}
+{{end}}### {{$group.Title}}
+
+{{range $group.Commits}}- {{with .Scope}}**{{.}}:** {{end}}{{.Subject}}{{if .Breaking}} (breaking){{end}}
+{{end}}{{end}}`,
+}
+
+// bodyTemplateFuncs are the functions the body templates can call besides the text/template ones
+var bodyTemplateFuncs = template.FuncMap{
+	"join":  strings.Join,
+	"trim":  strings.TrimSpace,
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+}
+
+// conventionalTypeTitles are the headings of the Conventional Commit types in the grouped body
+var conventionalTypeTitles = map[string]string{
+	"feat":     "Features",
+	"fix":      "Bug Fixes",
+	"perf":     "Performance",
+	"refactor": "Refactoring",
+	"revert":   "Reverts",
+	"docs":     "Documentation",
+	"test":     "Tests",
+	"build":    "Build",
+	"ci":       "Continuous Integration",
+	"style":    "Style",
+	"chore":    "Chores",
+}
+
+// otherChangesTitle is the heading of the commits that do not follow the Conventional Commits in the grouped body
+const otherChangesTitle = "Other Changes"
+
+// bodyCommit struct to represent a commit as the body templates see it
+type bodyCommit struct {
+	Sha      string
+	ShortSha string
+	Author   string
+	Message  string
+	// Summary is the first paragraph of the message and Body the rest of it
+	Summary string
+	Body    string
+	// Type, Scope and Breaking come from the Conventional Commit header, Type is empty when the summary does not follow it
+	Type     string
+	Scope    string
+	Breaking bool
+	// Subject is the summary without the Conventional Commit header
+	Subject   string
+	Trailers  []git_command.Trailer
+	IssueKeys []string
+}
+
+// Trailer method to get the values of the trailers with the key, ignoring its case
+func (c bodyCommit) Trailer(key string) []string {
+	values := make([]string, 0)
+	for _, trailer := range c.Trailers {
+		if strings.EqualFold(trailer.Key, key) {
+			values = append(values, trailer.Value)
+		}
+	}
+
+	return values
+}
+
+// bodyGroup struct to represent the commits of one Conventional Commit type
+type bodyGroup struct {
+	// Type is empty for the commits that do not follow the Conventional Commits
+	Type    string
+	Title   string
+	Commits []bodyCommit
+}
+
+// bodyData struct to represent what the body templates are executed with
+type bodyData struct {
+	Commits []bodyCommit
+	// Authors are the authors of the commits without duplicates, in the order of their first commit
+	Authors    []string
+	IssueKeys  []string
+	HeadBranch string
+	BaseBranch string
+	diffStats  func() (*git_command.DiffStats, error)
+}
+
+// Stats method to get the size of the changes, nil when the branches can not be compared locally.
+// The branches are only compared when a template asks for it.
+func (d bodyData) Stats() (*git_command.DiffStats, error) {
+	if d.diffStats == nil {
+		return nil, nil
+	}
+
+	return d.diffStats()
+}
+
+// Groups method to group the commits by Conventional Commit type, the types that matter most to a reader first
+// and the commits that do not follow the convention last
+func (d bodyData) Groups() []bodyGroup {
+	groups := make([]bodyGroup, 0)
+	for _, commit := range d.Commits {
+		i := slices.IndexFunc(groups, func(group bodyGroup) bool { return group.Type == commit.Type })
+		if i < 0 {
+			title := otherChangesTitle
+			if commit.Type != "" {
+				title = conventionalTypeTitles[commit.Type]
+				if title == "" {
+					title = capitalize(commit.Type)
+				}
+			}
+			groups = append(groups, bodyGroup{Type: commit.Type, Title: title})
+			i = len(groups) - 1
+		}
+		groups[i].Commits = append(groups[i].Commits, commit)
+	}
+
+	rank := func(kind string) int {
+		if kind == "" {
+			return len(conventionalTypeOrder) + 1
+		}
+
+		return conventionalTypeRank(kind)
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		if rank(groups[i].Type) != rank(groups[j].Type) {
+			return rank(groups[i].Type) < rank(groups[j].Type)
+		}
+
+		return groups[i].Type < groups[j].Type
+	})
+
+	return groups
+}
+
+// issueKeys function to get the issue keys the rules find in the inputs without duplicates
+func issueKeys(rules []config.IssueTrackerRule, inputs []string) []string {
+	keys := make([]string, 0)
+	for _, rule := range rules {
+		for _, input := range inputs {
+			links, err := extractIssueLinks(rule, input)
+			if err != nil {
+				log.Printf("Failed to extract issue keys: %s", err)
+				break
+			}
+			for _, link := range links {
+				keys = concatenateAndRemoveDuplicates(keys, []string{link.key})
+			}
+		}
+	}
+
+	return keys
+}
+
+// newBodyData function to prepare what the body templates are executed with.
+// diffStats is nil when the branches can not be compared.
+func newBodyData(
+	commits []gh_command.Commit,
+	rules []config.IssueTrackerRule,
+	headBranch string,
+	baseBranch string,
+	diffStats func() (*git_command.DiffStats, error),
+) bodyData {
+	data := bodyData{
+		Commits:    make([]bodyCommit, 0, len(commits)),
+		Authors:    make([]string, 0),
+		IssueKeys:  make([]string, 0),
+		HeadBranch: headBranch,
+		BaseBranch: baseBranch,
+		diffStats:  diffStats,
+	}
+	for _, commit := range commits {
+		summary, body := splitCommitSummaryAndDescription(commit.Message)
+		c := bodyCommit{
+			Sha:       commit.Sha,
+			ShortSha:  commit.Sha[:min(len(commit.Sha), 7)],
+			Author:    commit.Author,
+			Message:   commit.Message,
+			Summary:   summary,
+			Body:      body,
+			Subject:   summary,
+			Trailers:  commit.Trailers,
+			IssueKeys: issueKeys(rules, []string{summary, body}),
+		}
+		if header, ok := parseConventionalHeader(summary); ok {
+			c.Type, c.Scope, c.Breaking, c.Subject = header.kind, header.scope, header.breaking, header.subject
+		}
+
+		data.Commits = append(data.Commits, c)
+		if commit.Author != "" {
+			data.Authors = concatenateAndRemoveDuplicates(data.Authors, []string{commit.Author})
+		}
+		data.IssueKeys = concatenateAndRemoveDuplicates(data.IssueKeys, c.IssueKeys)
+	}
+
+	return data
+}
+
+// parseBodyTemplate function to parse a body template, with the functions the body templates can call
+func parseBodyTemplate(name string, text string) (*template.Template, error) {
+	return template.New(name).Funcs(bodyTemplateFuncs).Parse(text)
+}
+
+// renderBody function to execute a body template
+func renderBody(tmpl *template.Template, data bodyData) (string, error) {
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", err
+	}
+
+	return b.String(), nil
+}
+
+// bodyTemplate method to get the template the body is made with for the repository,
+// the custom template file when the config has one and the preset otherwise
+func (p *CreatePullRequest) bodyTemplate(ctx context.Context) (*template.Template, error) {
+	bodyConfig := p.config.BodyFor(p.repoOwner, p.repoName)
+	if bodyConfig.TemplateFile == "" {
+		return parseBodyTemplate(bodyConfig.PresetName(), bodyPresets[bodyConfig.PresetName()])
+	}
+
+	path := bodyConfig.TemplateFile
+	if !filepath.IsAbs(path) {
+		root, err := git_command.GetRepositoryRoot(ctx)
+		if err != nil {
+			return nil, err
+		}
+		path = filepath.Join(root, path)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the body template: %w", err)
+	}
+	tmpl, err := parseBodyTemplate(filepath.Base(path), string(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the body template: %w", err)
+	}
+
+	return tmpl, nil
+}
+
+// bodyDiffStats method to compare the branches locally for the body templates, only once however often they ask.
+// It gives nil when the base branch is not available locally.
+func (p *CreatePullRequest) bodyDiffStats(ctx context.Context) func() (*git_command.DiffStats, error) {
+	return sync.OnceValues(func() (*git_command.DiffStats, error) {
+		baseRef, ok, err := p.localBaseRef(ctx)
+		if err != nil || !ok {
+			return nil, err
+		}
+
+		stats, err := git_command.GetDiffStats(ctx, baseRef, p.headBranch)
+		if err != nil {
+			return nil, err
+		}
+
+		return &stats, nil
+	})
+}
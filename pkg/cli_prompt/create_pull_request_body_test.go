@@ -0,0 +1,110 @@
+package cli_prompt
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/coding-for-fun-org/lazygithub/pkg/config"
+	"github.com/coding-for-fun-org/lazygithub/pkg/gh_command"
+	"github.com/coding-for-fun-org/lazygithub/pkg/git_command"
+)
+
+func Test_renderBody(t *testing.T) {
+	commits := []gh_command.Commit{
+		{Sha: "1111111aaaa", Author: "Alice", Message: "feat(auth): add login for ABC-1"},
+		{Sha: "2222222bbbb", Author: "Bob", Message: "Update the README"},
+		{
+			Sha:      "3333333cccc",
+			Author:   "Alice",
+			Message:  "fix!: reject empty passwords\n\nSee ABC-2\n\nSigned-off-by: Alice <alice@example.com>",
+			Trailers: []git_command.Trailer{{Key: "Signed-off-by", Value: "Alice <alice@example.com>"}},
+		},
+		{Sha: "4444444dddd", Author: "Bob", Message: "feat: add logout"},
+	}
+	stats := func() (*git_command.DiffStats, error) {
+		return &git_command.DiffStats{Files: 4, Additions: 20, Deletions: 3}, nil
+	}
+	data := newBodyData(commits, []config.IssueTrackerRule{jiraRule}, "feature/login", "main", stats)
+
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{
+			name: "changelog preset",
+			text: bodyPresets[config.BodyPresetChangelog],
+			want: "- feat(auth): add login for ABC-1\n- Update the README\n- fix!: reject empty passwords\n- feat: add logout\n",
+		},
+		{
+			name: "grouped preset",
+			text: bodyPresets[config.BodyPresetGrouped],
+			want: "### Features\n\n- **auth:** add login for ABC-1\n- add logout\n" +
+				"\n### Bug Fixes\n\n- reject empty passwords (breaking)\n" +
+				"\n### Other Changes\n\n- Update the README\n",
+		},
+		{
+			name: "custom template",
+			text: "{{.HeadBranch}} into {{.BaseBranch}} by {{join .Authors \", \"}}, fixing {{join .IssueKeys \" \"}}\n" +
+				"{{with .Stats}}{{.Files}} files +{{.Additions}} -{{.Deletions}}{{end}}\n" +
+				"{{range .Commits}}{{range .Trailer \"signed-off-by\"}}{{.}}{{end}}{{end}} {{(index .Commits 2).ShortSha}}",
+			want: "feature/login into main by Alice, Bob, fixing ABC-1 ABC-2\n" +
+				"4 files +20 -3\n" +
+				"Alice <alice@example.com> 3333333",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := parseBodyTemplate(tt.name, tt.text)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := renderBody(tmpl, data)
+			if err != nil {
+				t.Fatalf("renderBody() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("renderBody() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreatePullRequest_bodyTemplate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "body.tmpl")
+	if err := os.WriteFile(path, []byte("{{len .Commits}} commits"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	p := &CreatePullRequest{
+		repoOwner: "owner",
+		repoName:  "repo",
+		config: config.Config{
+			Body: config.BodyConfig{Preset: config.BodyPresetChangelog},
+			Repositories: map[string]config.RepositoryConfig{
+				"owner/repo": {Body: config.BodyConfig{TemplateFile: path}},
+			},
+		},
+	}
+	tmpl, err := p.bodyTemplate(context.Background())
+	if err != nil {
+		t.Fatalf("CreatePullRequest.bodyTemplate() error = %v", err)
+	}
+	got, err := renderBody(tmpl, newBodyData(commitsOf("a", "b"), nil, "feature", "main", nil))
+	if err != nil || got != "2 commits" {
+		t.Errorf("CreatePullRequest.bodyTemplate() rendered %q, %v, want the repository template", got, err)
+	}
+
+	p.repoName = "other"
+	tmpl, err = p.bodyTemplate(context.Background())
+	if err != nil || tmpl.Name() != config.BodyPresetChangelog {
+		t.Errorf("CreatePullRequest.bodyTemplate() = %v, %v, want the global preset", tmpl.Name(), err)
+	}
+
+	p.config.Body = config.BodyConfig{TemplateFile: filepath.Join(t.TempDir(), "missing.tmpl")}
+	if _, err := p.bodyTemplate(context.Background()); err == nil {
+		t.Errorf("CreatePullRequest.bodyTemplate() error = nil, want a missing template file to fail")
+	}
+}
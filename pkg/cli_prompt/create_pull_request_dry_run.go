@@ -0,0 +1,90 @@
+package cli_prompt
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// dryRunRepository struct to represent the repository the pull request would be opened against
+type dryRunRepository struct {
+	ID    string `json:"id"`
+	Host  string `json:"host"`
+	Owner string `json:"owner"`
+	Name  string `json:"name"`
+}
+
+// dryRunRequest struct to represent what would be sent to GitHub, printed as JSON by a dry run
+type dryRunRequest struct {
+	// Action is "create", or "update" when the head branch already has an open pull request
+	Action     string           `json:"action"`
+	Repository dryRunRepository `json:"repository"`
+	// Number is the number of the pull request that would be updated
+	Number              int      `json:"number,omitempty"`
+	Head                string   `json:"head"`
+	Base                string   `json:"base"`
+	Title               string   `json:"title"`
+	Body                string   `json:"body"`
+	Reviewers           []string `json:"reviewers"`
+	TeamReviewers       []string `json:"teamReviewers"`
+	Draft               bool     `json:"draft"`
+	MaintainerCanModify *bool    `json:"maintainerCanModify,omitempty"`
+	Labels              []string `json:"labels"`
+	Assignees           []string `json:"assignees"`
+	Milestone           string   `json:"milestone,omitempty"`
+	Projects            []string `json:"projects"`
+}
+
+// orEmpty function to turn nil into an empty slice, so that the JSON has [] instead of null
+func orEmpty(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+
+	return values
+}
+
+// dryRunRequest method to describe the pull request that would be created or updated
+func (p *CreatePullRequest) dryRunRequest() dryRunRequest {
+	request := dryRunRequest{
+		Action: "create",
+		Repository: dryRunRepository{
+			ID:    p.repoId,
+			Host:  p.repoHost,
+			Owner: p.repoOwner,
+			Name:  p.repoName,
+		},
+		Head:          p.headRef(),
+		Base:          p.baseBranch,
+		Title:         p.title,
+		Body:          p.body,
+		Reviewers:     orEmpty(p.reviewers),
+		TeamReviewers: orEmpty(p.teamReviewers),
+		Draft:         p.isDraft,
+		Labels:        orEmpty(p.labels),
+		Assignees:     orEmpty(p.assignees),
+		Milestone:     p.milestone,
+		Projects:      orEmpty(p.projects),
+	}
+	if p.existing != nil {
+		request.Action = "update"
+		request.Number = p.existing.Number
+	}
+	if p.isFork() {
+		request.MaintainerCanModify = &p.maintainerCanModify
+	}
+
+	return request
+}
+
+// printDryRun method to write what would be sent to GitHub as an indented JSON document
+func (p *CreatePullRequest) printDryRun(w io.Writer) error {
+	content, err := json.MarshalIndent(p.dryRunRequest(), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(w, string(content))
+
+	return err
+}
@@ -0,0 +1,156 @@
+package cli_prompt
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/coding-for-fun-org/lazygithub/pkg/gh_command"
+)
+
+// initializeExistingPullRequest method to find the open pull request of the head branch.
+// When there is one, the prompt edits it instead of creating a duplicate, starting from its current values.
+func (p *CreatePullRequest) initializeExistingPullRequest(ctx context.Context) error {
+	existing, err := gh_command.FindOpenPullRequest(ctx, p.repoHost, p.repoOwner, p.repoName, p.headOwner(), p.headBranch)
+	if err != nil {
+		return fmt.Errorf("failed to look for an open pull request of %s: %w", p.headRef(), err)
+	}
+	p.existing = existing
+	if existing == nil {
+		return nil
+	}
+
+	p.title = existing.Title
+	p.body = existing.Body
+	p.isDraft = existing.IsDraft
+	p.maintainerCanModify = existing.MaintainerCanModify
+	if p.options.Reviewers == nil {
+		p.reviewers = append([]string{}, existing.RequestedReviewers...)
+		p.teamReviewers = append([]string{}, existing.RequestedTeams...)
+	}
+	if p.promptsMetadata() {
+		p.labels = make([]string, 0, len(existing.Labels))
+		for _, label := range existing.Labels {
+			p.labels = append(p.labels, label.Name)
+		}
+		p.assignees = append([]string{}, existing.Assignees...)
+		p.milestone = existing.Milestone
+		// The projects of a pull request can not be read without the read:project scope, so none is preselected
+		p.projects = []string{}
+	}
+
+	return nil
+}
+
+// difference function to get the values of a that are not in b
+func difference(a []string, b []string) []string {
+	values := make([]string, 0)
+	for _, value := range a {
+		if !slices.Contains(b, value) {
+			values = append(values, value)
+		}
+	}
+
+	return values
+}
+
+// sameValues function to check whether a and b hold the same values, in any order
+func sameValues(a []string, b []string) bool {
+	return len(difference(a, b)) == 0 && len(difference(b, a)) == 0
+}
+
+// editMetadata method to add the labels, assignees and milestone that changed to the edit of the existing pull request.
+// The unchanged ones are left out, because a closed milestone or an assignee who can no longer be assigned
+// can not be resolved and would otherwise be removed.
+func (p *CreatePullRequest) editMetadata(options *gh_command.UpdatePullRequestOptions) {
+	labelIDs, assigneeIDs, milestoneID := p.resolveMetadata()
+
+	existingLabels := make([]string, 0, len(p.existing.Labels))
+	for _, label := range p.existing.Labels {
+		existingLabels = append(existingLabels, label.Name)
+	}
+	if !sameValues(p.labels, existingLabels) {
+		options.LabelIDs = labelIDs
+	}
+	if !sameValues(p.assignees, p.existing.Assignees) {
+		options.AssigneeIDs = assigneeIDs
+	}
+	if p.milestone != p.existing.Milestone {
+		options.MilestoneID = &milestoneID
+	}
+}
+
+// submitEdit method to apply the edits to the existing pull request.
+// Only what changed is sent, and the reviewers that were removed in the form have their review request withdrawn.
+func (p *CreatePullRequest) submitEdit(ctx context.Context) (gh_command.PullRequest, error) {
+	existing := p.existing
+	options := gh_command.UpdatePullRequestOptions{Hostname: p.repoHost, PullRequestID: existing.ID}
+	if p.title != existing.Title {
+		options.Title = &p.title
+	}
+	if p.body != existing.Body {
+		options.Body = &p.body
+	}
+	if p.baseBranch != existing.BaseRefName {
+		options.BaseBranch = &p.baseBranch
+	}
+	if p.isFork() && p.maintainerCanModify != existing.MaintainerCanModify {
+		options.MaintainerCanModify = &p.maintainerCanModify
+	}
+	if p.promptsMetadata() {
+		p.editMetadata(&options)
+	}
+
+	failed := func(err error) error {
+		return fmt.Errorf("pull request #%d at %s was not fully updated: %w", existing.Number, existing.URL, err)
+	}
+
+	if err := gh_command.UpdatePullRequest(ctx, options); err != nil {
+		return existing.PullRequest, failed(fmt.Errorf("updating the pull request failed: %w", err))
+	}
+
+	if p.isDraft != existing.IsDraft {
+		if err := gh_command.SetPullRequestDraft(ctx, p.repoHost, existing.ID, p.isDraft); err != nil {
+			return existing.PullRequest, failed(fmt.Errorf("changing the draft state failed: %w", err))
+		}
+	}
+
+	err := gh_command.RequestReviews(
+		ctx,
+		p.repoHost,
+		existing.ID,
+		p.reviewerIDs(difference(p.reviewers, existing.RequestedReviewers)),
+		p.teamReviewerIDs(difference(p.teamReviewers, existing.RequestedTeams)),
+	)
+	if err != nil {
+		return existing.PullRequest, failed(fmt.Errorf("requesting reviewers failed: %w", err))
+	}
+
+	// Only the reviewers the picker offered can have been taken out, the others stay requested
+	withdrawnReviewers := requestableReviewers(
+		userCandidates(p.assignableUsers, p.myUserLogin),
+		difference(existing.RequestedReviewers, p.reviewers),
+	)
+	withdrawnTeams := requestableReviewers(
+		teamCandidates(p.teams, p.repoOwner),
+		difference(existing.RequestedTeams, p.teamReviewers),
+	)
+	err = gh_command.RemoveReviewRequests(
+		ctx,
+		p.repoHost,
+		p.repoOwner,
+		p.repoName,
+		existing.Number,
+		withdrawnReviewers,
+		withdrawnTeams,
+	)
+	if err != nil {
+		return existing.PullRequest, failed(fmt.Errorf("removing review requests failed: %w", err))
+	}
+
+	if err := p.addToProjects(ctx, existing.ID); err != nil {
+		return existing.PullRequest, failed(err)
+	}
+
+	return existing.PullRequest, nil
+}
@@ -0,0 +1,45 @@
+package cli_prompt
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/coding-for-fun-org/lazygithub/pkg/gh_command"
+)
+
+func TestCreatePullRequest_editMetadata(t *testing.T) {
+	existing := &gh_command.PullRequestDetail{
+		Labels: []gh_command.RepoLabel{{ID: "L_1", Name: "bug"}, {ID: "L_2", Name: "ui"}},
+		// The closed milestone and the former member can not be resolved
+		Assignees: []string{"alice", "former"},
+		Milestone: "Version 0.9",
+	}
+	newPullRequest := func() *CreatePullRequest {
+		return &CreatePullRequest{
+			existing:        existing,
+			repoLabels:      []gh_command.RepoLabel{{ID: "L_1", Name: "bug"}, {ID: "L_2", Name: "ui"}, {ID: "L_3", Name: "docs"}},
+			assignableUsers: []gh_command.RepoAssignableUser{{ID: "U_1", Login: "alice"}, {ID: "U_2", Login: "bob"}},
+			repoMilestones:  []gh_command.RepoMilestone{{ID: "M_1", Title: "Version 1.0"}},
+			labels:          []string{"ui", "bug"},
+			assignees:       []string{"former", "alice"},
+			milestone:       "Version 0.9",
+		}
+	}
+
+	options := gh_command.UpdatePullRequestOptions{}
+	newPullRequest().editMetadata(&options)
+	if !reflect.DeepEqual(options, gh_command.UpdatePullRequestOptions{}) {
+		t.Errorf("CreatePullRequest.editMetadata() = %+v, want nothing sent when nothing changed", options)
+	}
+
+	p := newPullRequest()
+	p.labels = []string{"bug"}
+	p.milestone = ""
+	options = gh_command.UpdatePullRequestOptions{}
+	p.editMetadata(&options)
+	milestoneID := ""
+	want := gh_command.UpdatePullRequestOptions{LabelIDs: []string{"L_1"}, MilestoneID: &milestoneID}
+	if !reflect.DeepEqual(options, want) {
+		t.Errorf("CreatePullRequest.editMetadata() = %+v, want only the labels and the removed milestone %+v", options, want)
+	}
+}
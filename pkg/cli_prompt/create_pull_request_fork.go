@@ -0,0 +1,124 @@
+package cli_prompt
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/charmbracelet/huh"
+	"github.com/coding-for-fun-org/lazygithub/pkg/git_command"
+)
+
+// remoteRepoName function to get the "host/owner/name" of the repository a remote points to
+func remoteRepoName(remote git_command.Remote) string {
+	return remote.Host + "/" + remote.Owner + "/" + remote.Repo
+}
+
+// forkRemotes function to find the "upstream" and "origin" remotes of a clone of a fork, on the given host when it is not empty.
+// Both are nil unless the two remotes exist and point to different repositories.
+func forkRemotes(remotes []git_command.Remote, hostname string) (*git_command.Remote, *git_command.Remote) {
+	var upstream, origin *git_command.Remote
+	for i, remote := range remotes {
+		if hostname != "" && !strings.EqualFold(remote.Host, hostname) {
+			continue
+		}
+		switch remote.Name {
+		case "upstream":
+			upstream = &remotes[i]
+		case "origin":
+			origin = &remotes[i]
+		}
+	}
+	if upstream == nil || origin == nil || strings.EqualFold(remoteRepoName(*upstream), remoteRepoName(*origin)) {
+		return nil, nil
+	}
+
+	return upstream, origin
+}
+
+// chooseTargetRepository method to ask which repository the pull request is opened against
+// when the clone has an "upstream" and an "origin" remote pointing to different repositories, like a clone of a fork.
+// Without a terminal or with --yes, upstream is chosen like gh does.
+func (p *CreatePullRequest) chooseTargetRepository(ctx context.Context, interactive bool) error {
+	if p.options.Repo != "" || !interactive || p.options.Yes {
+		return nil
+	}
+
+	remotes, err := git_command.ListRemotes(ctx)
+	if err != nil {
+		// Resolving the repository from the remotes fails the same way right after
+		return nil
+	}
+	upstream, origin := forkRemotes(remotes, p.options.Hostname)
+	if upstream == nil {
+		return nil
+	}
+
+	target := remoteRepoName(*upstream)
+	targetForm := huh.NewForm(
+		huh.NewGroup(
+			huh.NewSelect[string]().
+				Title("Select the repository to open the pull request against").
+				Options(
+					huh.NewOption(fmt.Sprintf("%s/%s (upstream)", upstream.Owner, upstream.Repo), remoteRepoName(*upstream)),
+					huh.NewOption(fmt.Sprintf("%s/%s (origin)", origin.Owner, origin.Repo), remoteRepoName(*origin)),
+				).
+				Value(&target),
+		),
+	)
+	if err := targetForm.Run(); err != nil {
+		return err
+	}
+	if targetForm.State == huh.StateAborted {
+		return ErrAborted
+	}
+	p.options.Repo = target
+
+	return nil
+}
+
+// initializeHeadRepository method to find the fork the head branch is pushed to.
+// Like for choosing the target repository, the "origin" remote is only taken as the fork
+// when the clone also has an "upstream" remote and the pull request is opened against it.
+// Otherwise the head branch is in the base repository, since any other origin may be unrelated to it.
+func (p *CreatePullRequest) initializeHeadRepository(ctx context.Context) {
+	p.headRepoOwner, p.headRepoName = "", ""
+
+	remotes, err := git_command.ListRemotes(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("Failed to list the git remotes, the head branch is looked for in %s/%s: %s", p.repoOwner, p.repoName, err)
+		}
+		return
+	}
+	upstream, origin := forkRemotes(remotes, p.repoHost)
+	if upstream == nil || !strings.EqualFold(upstream.Owner, p.repoOwner) || !strings.EqualFold(upstream.Repo, p.repoName) {
+		return
+	}
+	p.headRepoOwner, p.headRepoName = origin.Owner, origin.Repo
+}
+
+// isFork method to check whether the head branch is in a fork of the base repository
+func (p *CreatePullRequest) isFork() bool {
+	return p.headRepoOwner != ""
+}
+
+// headOwner method to get the owner of the repository of the head branch
+func (p *CreatePullRequest) headOwner() string {
+	if p.isFork() {
+		return p.headRepoOwner
+	}
+
+	return p.repoOwner
+}
+
+// headRef method to get the head branch the way GitHub refers to it from the base repository,
+// "owner:branch" when it is in a fork
+func (p *CreatePullRequest) headRef() string {
+	if p.isFork() {
+		return p.headRepoOwner + ":" + p.headBranch
+	}
+
+	return p.headBranch
+}
@@ -0,0 +1,54 @@
+package cli_prompt
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/coding-for-fun-org/lazygithub/pkg/command_runner"
+)
+
+func TestCreatePullRequest_initializeHeadRepository(t *testing.T) {
+	forkClone := "origin\tgit@github.example.com:fork/repo.git (fetch)\nupstream\tgit@github.example.com:owner/repo.git (fetch)\n"
+	tests := []struct {
+		name      string
+		remotes   string
+		repo      string
+		wantOwner string
+	}{
+		{
+			name:      "origin is the fork of upstream",
+			remotes:   forkClone,
+			repo:      "owner/repo",
+			wantOwner: "fork",
+		},
+		{
+			name:    "the pull request is opened against origin itself",
+			remotes: forkClone,
+			repo:    "fork/repo",
+		},
+		{
+			name:    "another repository given with --repo",
+			remotes: forkClone,
+			repo:    "other/x",
+		},
+		{
+			name:    "origin still points to the name before a rename",
+			remotes: "origin\tgit@github.example.com:owner/old-name.git (fetch)\n",
+			repo:    "owner/repo",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer command_runner.SetDefault(command_runner.NewFakeRunner().
+				Add(command_runner.FakeResponse{Stdout: tt.remotes}, "git", "remote", "-v"))()
+
+			p := &CreatePullRequest{repoHost: "github.example.com"}
+			p.repoOwner, p.repoName, _ = strings.Cut(tt.repo, "/")
+			p.initializeHeadRepository(context.Background())
+			if p.headRepoOwner != tt.wantOwner {
+				t.Errorf("CreatePullRequest.initializeHeadRepository() head owner = %q, want %q", p.headRepoOwner, tt.wantOwner)
+			}
+		})
+	}
+}
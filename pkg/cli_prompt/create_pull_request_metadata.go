@@ -0,0 +1,176 @@
+package cli_prompt
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/charmbracelet/huh"
+	"github.com/coding-for-fun-org/lazygithub/pkg/gh_command"
+)
+
+// preselectMetadata method to preselect the labels, assignees, milestone and projects chosen for the last pull request,
+// leaving out the ones that do not exist in the repository anymore
+func (p *CreatePullRequest) preselectMetadata() {
+	p.labels = []string{}
+	p.assignees = []string{}
+	p.projects = []string{}
+	if p.latestMetadata == nil {
+		return
+	}
+
+	labelNames := make([]string, 0, len(p.repoLabels))
+	for _, label := range p.repoLabels {
+		labelNames = append(labelNames, label.Name)
+	}
+	p.labels = keepKnown(p.latestMetadata.Labels, labelNames)
+
+	logins := make([]string, 0, len(p.assignableUsers))
+	for _, user := range p.assignableUsers {
+		logins = append(logins, user.Login)
+	}
+	p.assignees = keepKnown(p.latestMetadata.Assignees, logins)
+
+	for _, milestone := range p.repoMilestones {
+		if milestone.Title == p.latestMetadata.Milestone {
+			p.milestone = milestone.Title
+		}
+	}
+
+	projectTitles := make([]string, 0, len(p.repoProjects))
+	for _, project := range p.repoProjects {
+		projectTitles = append(projectTitles, project.Title)
+	}
+	p.projects = keepKnown(p.latestMetadata.Projects, projectTitles)
+}
+
+// metadataGroup method to create the form group for the labels, assignees, milestone and projects.
+// It returns nil when they are not asked for, a field is left out when the repository has nothing to choose from.
+func (p *CreatePullRequest) metadataGroup() *huh.Group {
+	if !p.promptsMetadata() {
+		return nil
+	}
+
+	fields := make([]huh.Field, 0, 4)
+
+	if len(p.repoLabels) > 0 {
+		options := make([]huh.Option[string], 0, len(p.repoLabels))
+		for _, label := range p.repoLabels {
+			options = append(options, huh.NewOption(label.Name, label.Name))
+		}
+		fields = append(
+			fields,
+			huh.NewMultiSelect[string]().
+				Title("Select labels").
+				Options(options...).
+				Value(&p.labels),
+		)
+	}
+
+	if len(p.assignableUsers) > 0 {
+		fields = append(
+			fields,
+			huh.NewMultiSelect[string]().
+				Title("Select assignees").
+				Options(assigneeOptions(p.assignableUsers, p.myUserLogin)...).
+				Value(&p.assignees),
+		)
+	}
+
+	if len(p.repoMilestones) > 0 {
+		options := make([]huh.Option[string], 0, len(p.repoMilestones)+1)
+		options = append(options, huh.NewOption("No milestone", ""))
+		for _, milestone := range p.repoMilestones {
+			options = append(options, huh.NewOption(milestone.Title, milestone.Title))
+		}
+		fields = append(
+			fields,
+			huh.NewSelect[string]().
+				Title("Select the milestone").
+				Options(options...).
+				Value(&p.milestone),
+		)
+	}
+
+	if len(p.repoProjects) > 0 {
+		options := make([]huh.Option[string], 0, len(p.repoProjects))
+		for _, project := range p.repoProjects {
+			options = append(options, huh.NewOption(project.Title, project.Title))
+		}
+		fields = append(
+			fields,
+			huh.NewMultiSelect[string]().
+				Title("Select projects").
+				Options(options...).
+				Value(&p.projects),
+		)
+	}
+
+	if len(fields) == 0 {
+		return nil
+	}
+
+	return huh.NewGroup(fields...)
+}
+
+// resolveMetadata method to resolve the chosen labels, assignees and milestone into node IDs,
+// the milestone ID is empty when no milestone was chosen
+func (p *CreatePullRequest) resolveMetadata() ([]string, []string, string) {
+	labelIDs := make([]string, 0, len(p.labels))
+	for _, label := range p.repoLabels {
+		if slices.Contains(p.labels, label.Name) {
+			labelIDs = append(labelIDs, label.ID)
+		}
+	}
+
+	assigneeIDs := make([]string, 0, len(p.assignees))
+	for _, user := range p.assignableUsers {
+		if slices.Contains(p.assignees, user.Login) {
+			assigneeIDs = append(assigneeIDs, user.ID)
+		}
+	}
+
+	milestoneID := ""
+	for _, milestone := range p.repoMilestones {
+		if p.milestone != "" && milestone.Title == p.milestone {
+			milestoneID = milestone.ID
+		}
+	}
+
+	return labelIDs, assigneeIDs, milestoneID
+}
+
+// submitMetadata method to set the chosen labels, assignees and milestone on a new pull request and add it to the projects
+func (p *CreatePullRequest) submitMetadata(ctx context.Context, pullRequestID string) error {
+	labelIDs, assigneeIDs, milestoneID := p.resolveMetadata()
+
+	options := gh_command.UpdatePullRequestOptions{Hostname: p.repoHost, PullRequestID: pullRequestID}
+	if len(labelIDs) > 0 {
+		options.LabelIDs = labelIDs
+	}
+	if len(assigneeIDs) > 0 {
+		options.AssigneeIDs = assigneeIDs
+	}
+	if milestoneID != "" {
+		options.MilestoneID = &milestoneID
+	}
+	if err := gh_command.UpdatePullRequest(ctx, options); err != nil {
+		return fmt.Errorf("setting the labels, assignees and milestone failed: %w", err)
+	}
+
+	return p.addToProjects(ctx, pullRequestID)
+}
+
+// addToProjects method to add the pull request to the chosen projects
+func (p *CreatePullRequest) addToProjects(ctx context.Context, pullRequestID string) error {
+	for _, project := range p.repoProjects {
+		if !slices.Contains(p.projects, project.Title) {
+			continue
+		}
+		if err := gh_command.AddToProject(ctx, p.repoHost, project.ID, pullRequestID); err != nil {
+			return fmt.Errorf("adding it to the project %s failed: %w", project.Title, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,265 @@
+package cli_prompt
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/huh/spinner"
+	"github.com/mattn/go-isatty"
+)
+
+// isInteractive function to check whether the prompts can be shown to the user.
+// It is a variable so that tests do not depend on how they are run.
+var isInteractive = func() bool {
+	fd := os.Stdin.Fd()
+
+	return isatty.IsTerminal(fd) || isatty.IsCygwinTerminal(fd)
+}
+
+// runWithSpinner function to run the action behind a spinner, or directly when there is no terminal,
+// and return the error of the action.
+// Pressing ctrl+c stops the spinner and cancels the context of the action, which kills its child processes.
+func runWithSpinner(ctx context.Context, title string, action func(ctx context.Context) error) error {
+	if !isInteractive() {
+		return action(ctx)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// The spinner runs until its context is done, so it is stopped once the action returns
+	spinnerCtx, stopSpinner := context.WithCancel(ctx)
+	defer stopSpinner()
+	done := make(chan error, 1)
+	go func() {
+		done <- action(ctx)
+		stopSpinner()
+	}()
+
+	if err := spinner.New().Title(title).Context(spinnerCtx).Run(); err != nil {
+		cancel()
+		<-done
+		return err
+	}
+
+	select {
+	case err := <-done:
+		return err
+	default:
+		// The spinner was stopped by the user, the action is waited for so that no child process is left behind
+		cancel()
+		<-done
+		return ErrAborted
+	}
+}
+
+// readBodyFile function to read the pull request body from a file, "-" reads from stdin
+func readBodyFile(path string) (string, error) {
+	if path == "-" {
+		content, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return "", fmt.Errorf("failed to read the body from stdin: %w", err)
+		}
+
+		return string(content), nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the body file: %w", err)
+	}
+
+	return string(content), nil
+}
+
+// promptsHeadBranch method to check whether the head branch has to be asked for
+func (p *CreatePullRequest) promptsHeadBranch() bool {
+	return p.options.Head == ""
+}
+
+// promptsBaseBranch method to check whether the base branch has to be asked for
+func (p *CreatePullRequest) promptsBaseBranch() bool {
+	return p.options.Base == "" && !p.options.Yes
+}
+
+// promptsTitle method to check whether the title has to be asked for.
+// With --yes the prepopulated title is used, unless there is none.
+func (p *CreatePullRequest) promptsTitle() bool {
+	return p.options.Title == "" && !p.options.Yes
+}
+
+// promptsBody method to check whether the body has to be asked for
+func (p *CreatePullRequest) promptsBody() bool {
+	return p.options.Body == "" && p.options.BodyFile == "" && !p.options.Yes
+}
+
+// promptsReviewers method to check whether the reviewers have to be asked for
+func (p *CreatePullRequest) promptsReviewers() bool {
+	return p.options.Reviewers == nil && !p.options.Yes
+}
+
+// needsTeams method to check whether the teams of the organization have to be loaded,
+// which is when reviewers are chosen or preselected, or a team was given from the command line
+func (p *CreatePullRequest) needsTeams() bool {
+	if p.options.Reviewers == nil {
+		return true
+	}
+	_, teams := splitTeamReviewers(p.options.Reviewers)
+
+	return len(teams) > 0
+}
+
+// promptsMetadata method to check whether the labels, assignees, milestone and projects have to be asked for.
+// They are optional, so they are only asked for in a terminal and left out with --yes.
+func (p *CreatePullRequest) promptsMetadata() bool {
+	return !p.options.Yes && isInteractive()
+}
+
+// promptsDraft method to check whether the draft state has to be asked for
+func (p *CreatePullRequest) promptsDraft() bool {
+	return p.options.Draft == nil && !p.options.Yes
+}
+
+// promptsMaintainerCanModify method to check whether it has to be asked if the maintainers may push to the head branch.
+// It only matters for a fork and GitHub allows it by default, so it is only asked for in a terminal and left out with --yes.
+func (p *CreatePullRequest) promptsMaintainerCanModify() bool {
+	return p.isFork() && p.options.MaintainerCanModify == nil && !p.options.Yes && isInteractive()
+}
+
+// missingOptions method to list the values that would have to be prompted for
+func (p *CreatePullRequest) missingOptions() []string {
+	missing := make([]string, 0)
+	if p.promptsHeadBranch() {
+		missing = append(missing, "head")
+	}
+	if p.promptsBaseBranch() {
+		missing = append(missing, "base")
+	}
+	if p.promptsTitle() {
+		missing = append(missing, "title")
+	}
+	if p.promptsBody() {
+		missing = append(missing, "body")
+	}
+	if p.promptsReviewers() {
+		missing = append(missing, "reviewers")
+	}
+	if p.promptsDraft() {
+		missing = append(missing, "draft")
+	}
+
+	return missing
+}
+
+// missingOptionsError function to build the error returned when prompts can not be shown
+func missingOptionsError(missing []string) error {
+	return fmt.Errorf(
+		"stdin is not a terminal, so the following values must be given as flags: %s",
+		strings.Join(missing, ", "),
+	)
+}
+
+// applyBranchOptions method to fill the branches that were given from the command line
+func (p *CreatePullRequest) applyBranchOptions() {
+	if p.options.Head != "" {
+		p.headBranch = p.options.Head
+	}
+	if p.options.Base != "" {
+		p.baseBranch = p.options.Base
+	}
+}
+
+// initializeBaseBranch method to start from the base of the open pull request when none was given,
+// so that editing it only moves the base on request, and from the default branch otherwise
+func (p *CreatePullRequest) initializeBaseBranch() {
+	if p.options.Base != "" {
+		return
+	}
+	if p.existing != nil {
+		p.baseBranch = p.existing.BaseRefName
+		return
+	}
+	p.baseBranch = p.defaultBranch
+}
+
+// applyTemplateOptions method to choose the pull request template given from the command line,
+// defaulting to the one GitHub would use
+func (p *CreatePullRequest) applyTemplateOptions() error {
+	switch p.options.Template {
+	case "":
+		if len(p.templates) > 0 {
+			p.templatePath = p.templates[0].path
+		}
+	case "none":
+		p.templatePath = ""
+	default:
+		p.templatePath = filepath.Clean(p.options.Template)
+		if p.selectedTemplate() == nil {
+			return fmt.Errorf("pull request template %s was not found", p.options.Template)
+		}
+	}
+
+	return nil
+}
+
+// applyRestOptions method to fill the title, body, reviewers and draft state that were given from the command line
+func (p *CreatePullRequest) applyRestOptions() error {
+	if p.options.Title != "" {
+		p.title = p.options.Title
+	}
+	if p.options.Body != "" {
+		p.body = p.options.Body
+	} else if p.options.BodyFile != "" {
+		body, err := readBodyFile(p.options.BodyFile)
+		if err != nil {
+			return err
+		}
+		p.body = body
+	}
+	if p.options.Reviewers != nil {
+		if err := p.validateReviewers(p.options.Reviewers); err != nil {
+			return err
+		}
+		p.reviewers, p.teamReviewers = splitTeamReviewers(p.options.Reviewers)
+	}
+	if p.options.Draft != nil {
+		p.isDraft = *p.options.Draft
+	}
+	if p.options.MaintainerCanModify != nil {
+		p.maintainerCanModify = *p.options.MaintainerCanModify
+	}
+
+	return nil
+}
+
+// validateReviewers method to make sure that every given reviewer can be requested on the repository
+func (p *CreatePullRequest) validateReviewers(reviewers []string) error {
+	requestable := make(map[string]struct{})
+	for _, user := range p.assignableUsers {
+		requestable[user.Login] = struct{}{}
+	}
+	for _, team := range teamCandidates(p.teams, p.repoOwner) {
+		requestable[team.value] = struct{}{}
+	}
+
+	unknown := make([]string, 0)
+	for _, reviewer := range reviewers {
+		if _, ok := requestable[reviewer]; !ok {
+			unknown = append(unknown, reviewer)
+		}
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf(
+			"the following reviewers can not be requested on %s/%s: %s",
+			p.repoOwner,
+			p.repoName,
+			strings.Join(unknown, ", "),
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,147 @@
+package cli_prompt
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/huh"
+	"github.com/coding-for-fun-org/lazygithub/pkg/git_command"
+	"golang.org/x/term"
+)
+
+// previewAction is what the user chooses to do on the preview of the pull request
+type previewAction int
+
+const (
+	previewSubmit previewAction = iota
+	previewEdit
+	previewCancel
+)
+
+// defaultPreviewWidth is the width the preview is wrapped at when the width of the terminal is unknown
+const defaultPreviewWidth = 80
+
+// previewMarkdown method to describe the pull request that is about to be submitted as a markdown document.
+// stats is nil when the branches could not be compared locally.
+func (p *CreatePullRequest) previewMarkdown(stats *git_command.DiffStats) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# %s\n\n", p.title)
+	if p.existing != nil {
+		fmt.Fprintf(&b, "- **Updates:** #%d\n", p.existing.Number)
+	}
+	fmt.Fprintf(&b, "- **Branches:** `%s` → `%s`\n", p.headRef(), p.baseBranch)
+	if p.isDraft {
+		b.WriteString("- **State:** draft\n")
+	} else {
+		b.WriteString("- **State:** ready for review\n")
+	}
+	if stats != nil {
+		fmt.Fprintf(&b, "- **Changes:** %d files, +%d −%d\n", stats.Files, stats.Additions, stats.Deletions)
+	}
+
+	reviewers := append(append([]string{}, p.reviewers...), p.teamReviewers...)
+	if len(reviewers) == 0 {
+		b.WriteString("- **Reviewers:** none\n")
+	} else {
+		fmt.Fprintf(&b, "- **Reviewers:** %s\n", strings.Join(reviewers, ", "))
+	}
+	milestones := []string{}
+	if p.milestone != "" {
+		milestones = append(milestones, p.milestone)
+	}
+	for _, field := range []struct {
+		name   string
+		values []string
+	}{
+		{name: "Labels", values: p.labels},
+		{name: "Assignees", values: p.assignees},
+		{name: "Milestone", values: milestones},
+		{name: "Projects", values: p.projects},
+	} {
+		if len(field.values) > 0 {
+			fmt.Fprintf(&b, "- **%s:** %s\n", field.name, strings.Join(field.values, ", "))
+		}
+	}
+
+	b.WriteString("\n---\n\n")
+	if strings.TrimSpace(p.body) == "" {
+		b.WriteString("_No description provided._\n")
+	} else {
+		b.WriteString(p.body)
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// renderMarkdown function to render markdown for the terminal, wrapped at its width
+func renderMarkdown(markdown string) (string, error) {
+	width := defaultPreviewWidth
+	if w, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil && w > 0 {
+		width = w
+	}
+
+	renderer, err := glamour.NewTermRenderer(glamour.WithAutoStyle(), glamour.WithWordWrap(width))
+	if err != nil {
+		return "", err
+	}
+
+	return renderer.Render(markdown)
+}
+
+// confirmPreview method to show the rendered pull request and ask whether to submit it, edit it again or cancel
+func (p *CreatePullRequest) confirmPreview(ctx context.Context) (previewAction, error) {
+	var stats *git_command.DiffStats
+	baseRef, ok, err := p.localBaseRef(ctx)
+	if err == nil && ok {
+		var diffStats git_command.DiffStats
+		diffStats, err = git_command.GetDiffStats(ctx, baseRef, p.headBranch)
+		if err == nil {
+			stats = &diffStats
+		}
+	}
+	if err != nil {
+		// The preview is still useful without the size of the changes
+		log.Printf("Failed to compare %s with %s: %s", p.headBranch, p.baseBranch, err)
+	}
+
+	markdown := p.previewMarkdown(stats)
+	rendered, err := renderMarkdown(markdown)
+	if err != nil {
+		log.Printf("Failed to render the preview: %s", err)
+		rendered = markdown
+	}
+	fmt.Print(rendered)
+
+	title, submitLabel := "Create the pull request?", "Create"
+	if p.existing != nil {
+		title, submitLabel = fmt.Sprintf("Update pull request #%d?", p.existing.Number), "Update"
+	}
+
+	action := previewSubmit
+	previewForm := huh.NewForm(
+		huh.NewGroup(
+			huh.NewSelect[previewAction]().
+				Title(title).
+				Options(
+					huh.NewOption(submitLabel, previewSubmit),
+					huh.NewOption("Edit", previewEdit),
+					huh.NewOption("Cancel", previewCancel),
+				).
+				Value(&action),
+		),
+	)
+	if err := previewForm.Run(); err != nil {
+		return previewCancel, err
+	}
+	if previewForm.State == huh.StateAborted {
+		return previewCancel, nil
+	}
+
+	return action, nil
+}
@@ -0,0 +1,45 @@
+package cli_prompt
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/coding-for-fun-org/lazygithub/pkg/gh_command"
+	"github.com/coding-for-fun-org/lazygithub/pkg/git_command"
+)
+
+func TestCreatePullRequest_previewMarkdown(t *testing.T) {
+	p := &CreatePullRequest{
+		repoOwner:     "owner",
+		headRepoOwner: "fork",
+		headBranch:    "feature",
+		baseBranch:    "main",
+		title:         "feat(ABC-123): add login",
+		body:          "The login page\n\n### Jira Link\n\n[ABC-123](https://example.atlassian.net/browse/ABC-123)",
+		reviewers:     []string{"alice"},
+		teamReviewers: []string{"owner/backend"},
+		isDraft:       true,
+		labels:        []string{"feature"},
+		milestone:     "Version 1.0",
+	}
+
+	want := "# feat(ABC-123): add login\n\n" +
+		"- **Branches:** `fork:feature` → `main`\n" +
+		"- **State:** draft\n" +
+		"- **Changes:** 3 files, +13 −2\n" +
+		"- **Reviewers:** alice, owner/backend\n" +
+		"- **Labels:** feature\n" +
+		"- **Milestone:** Version 1.0\n" +
+		"\n---\n\n" +
+		"The login page\n\n### Jira Link\n\n[ABC-123](https://example.atlassian.net/browse/ABC-123)\n"
+	if got := p.previewMarkdown(&git_command.DiffStats{Files: 3, Additions: 13, Deletions: 2}); got != want {
+		t.Errorf("CreatePullRequest.previewMarkdown() = %q, want %q", got, want)
+	}
+
+	p.existing = &gh_command.PullRequestDetail{PullRequest: gh_command.PullRequest{Number: 7}}
+	p.body = ""
+	got := p.previewMarkdown(nil)
+	if !strings.Contains(got, "- **Updates:** #7\n") || strings.Contains(got, "Changes") || !strings.HasSuffix(got, "_No description provided._\n") {
+		t.Errorf("CreatePullRequest.previewMarkdown() = %q, want the edited pull request without the unknown changes", got)
+	}
+}
@@ -0,0 +1,178 @@
+package cli_prompt
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/huh"
+	"github.com/coding-for-fun-org/lazygithub/pkg/git_command"
+)
+
+// headPush struct to represent the push the head branch needs before GitHub can compare it with the base branch
+type headPush struct {
+	remote  string
+	options git_command.PushOptions
+	// question is asked before pushing
+	question string
+}
+
+// tracksSameName function to check whether the upstream of the branch is the branch with the same name on the remote,
+// the one the pull request compares and PushBranch pushes to
+func tracksSameName(branch string, status git_command.BranchStatus) bool {
+	return status.Upstream != "" && status.UpstreamRef == "refs/heads/"+branch
+}
+
+// planHeadPush function to decide how the head branch has to be pushed, nil when the remote already has it.
+// defaultRemote is used when the branch has no upstream yet, or one with another name that says nothing about
+// the branch the pull request compares.
+func planHeadPush(branch string, status git_command.BranchStatus, defaultRemote string) *headPush {
+	switch {
+	case status.Upstream == "":
+		return &headPush{
+			remote:   defaultRemote,
+			options:  git_command.PushOptions{SetUpstream: true},
+			question: fmt.Sprintf("%s was never pushed. Push it to %s?", branch, defaultRemote),
+		}
+	case !tracksSameName(branch, status):
+		return &headPush{
+			remote:  defaultRemote,
+			options: git_command.PushOptions{SetUpstream: true},
+			question: fmt.Sprintf(
+				"%s tracks %s, which has another name. Push it to %s/%s and track that instead?",
+				branch,
+				status.Upstream,
+				defaultRemote,
+				branch,
+			),
+		}
+	case status.Gone:
+		return &headPush{
+			remote:   status.Remote,
+			options:  git_command.PushOptions{SetUpstream: true},
+			question: fmt.Sprintf("%s was deleted on %s. Push it again?", status.Upstream, status.Remote),
+		}
+	case status.Ahead > 0 && status.Behind > 0:
+		return &headPush{
+			remote:  status.Remote,
+			options: git_command.PushOptions{ForceWithLease: true},
+			question: fmt.Sprintf(
+				"%s and %s have diverged (%d ahead, %d behind), probably after a rebase. Force push with lease?",
+				branch,
+				status.Upstream,
+				status.Ahead,
+				status.Behind,
+			),
+		}
+	case status.Ahead > 0:
+		return &headPush{
+			remote:   status.Remote,
+			question: fmt.Sprintf("%s is %d commits ahead of %s. Push it?", branch, status.Ahead, status.Upstream),
+		}
+	default:
+		// Being behind only means the remote has more commits, which the pull request shows anyway
+		return nil
+	}
+}
+
+// repositoryRemote method to find the git remote pointing to a repository on the host of the pull request,
+// false when there is none
+func (p *CreatePullRequest) repositoryRemote(ctx context.Context, owner string, name string) (string, bool, error) {
+	remotes, err := git_command.ListRemotes(ctx)
+	if err != nil {
+		return "", false, err
+	}
+	for _, remote := range remotes {
+		if strings.EqualFold(remote.Host, p.repoHost) &&
+			strings.EqualFold(remote.Owner, owner) &&
+			strings.EqualFold(remote.Repo, name) {
+			return remote.Name, true, nil
+		}
+	}
+
+	return "", false, nil
+}
+
+// defaultPushRemote method to find the git remote of the repository the head branch belongs to,
+// the fork when there is one and otherwise the repository the pull request is created on
+func (p *CreatePullRequest) defaultPushRemote(ctx context.Context) (string, error) {
+	owner, name := p.repoOwner, p.repoName
+	if p.isFork() {
+		owner, name = p.headRepoOwner, p.headRepoName
+	}
+
+	remote, ok, err := p.repositoryRemote(ctx, owner, name)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", fmt.Errorf("no git remote points to %s/%s/%s to push %s to", p.repoHost, owner, name, p.headBranch)
+	}
+
+	return remote, nil
+}
+
+// pushHeadBranch method to push the head branch when the remote does not have its latest commits,
+// asking first unless --push or --yes was given. A force push needs --push, --yes does not confirm it.
+// A head branch that only exists on the remote is left alone, and so is the remote on a dry run.
+func (p *CreatePullRequest) pushHeadBranch(ctx context.Context, interactive bool) error {
+	if p.options.DryRun || (p.options.Push != nil && !*p.options.Push) {
+		return nil
+	}
+
+	status, ok, err := git_command.GetBranchStatus(ctx, p.headBranch)
+	if err != nil {
+		return fmt.Errorf("failed to check the upstream of %s: %w", p.headBranch, err)
+	}
+	if !ok {
+		return nil
+	}
+
+	defaultRemote := ""
+	if !tracksSameName(p.headBranch, status) {
+		defaultRemote, err = p.defaultPushRemote(ctx)
+		if err != nil {
+			return err
+		}
+	}
+	push := planHeadPush(p.headBranch, status, defaultRemote)
+	if push == nil {
+		return nil
+	}
+
+	if p.options.Push == nil && p.options.Yes && push.options.ForceWithLease {
+		// --yes is not enough to overwrite the commits of the remote, only an explicit --push is
+		return fmt.Errorf("%s and %s have diverged, give --push to force push it with lease", p.headBranch, status.Upstream)
+	}
+	if p.options.Push == nil && !p.options.Yes {
+		if !interactive {
+			return missingOptionsError([]string{"push"})
+		}
+
+		confirmed := true
+		pushForm := huh.NewForm(
+			huh.NewGroup(
+				huh.NewConfirm().
+					Title(push.question).
+					Value(&confirmed),
+			),
+		)
+		if err := pushForm.Run(); err != nil {
+			return err
+		}
+		if pushForm.State == huh.StateAborted {
+			return ErrAborted
+		}
+		if !confirmed {
+			return nil
+		}
+	}
+
+	return runWithSpinner(ctx, fmt.Sprintf("Pushing %s to %s", p.headBranch, push.remote), func(ctx context.Context) error {
+		if err := git_command.PushBranch(ctx, push.remote, p.headBranch, push.options); err != nil {
+			return fmt.Errorf("failed to push %s to %s: %w", p.headBranch, push.remote, err)
+		}
+
+		return nil
+	})
+}
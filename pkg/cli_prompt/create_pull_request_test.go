@@ -0,0 +1,889 @@
+package cli_prompt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/coding-for-fun-org/lazygithub/pkg/command_runner"
+	"github.com/coding-for-fun-org/lazygithub/pkg/gh_command"
+	"github.com/coding-for-fun-org/lazygithub/pkg/git_command"
+	"github.com/coding-for-fun-org/lazygithub/pkg/state"
+)
+
+// graphqlRequest struct to represent the payload sent to gh api graphql
+type graphqlRequest struct {
+	Query     string `json:"query"`
+	Variables struct {
+		Input map[string]interface{} `json:"input"`
+	} `json:"variables"`
+}
+
+// testRepository is the response of the repository query, owned by a user
+const testRepository = `{"data": {"repository": {
+	"id": "R_1",
+	"name": "repo",
+	"owner": {"__typename": "User", "id": "O_1", "login": "owner"},
+	"defaultBranchRef": {"name": "main"},
+	"assignableUsers": {
+		"nodes": [{"id": "U_1", "login": "alice", "name": "Alice"}, {"id": "U_2", "login": "bob", "name": ""}],
+		"pageInfo": {"hasNextPage": false, "endCursor": null}
+	}
+}}}`
+
+// noOpenPullRequest is the response of the open pull request query when the head branch has none
+const noOpenPullRequest = `{"data": {"repository": {"pullRequests": {"nodes": []}}}}`
+
+// setupCreatePullRequestTest function to isolate the test from the user environment
+// and fake the commands that every flow runs before submitting
+func setupCreatePullRequestTest(t *testing.T) *command_runner.FakeRunner {
+	t.Helper()
+
+	return setupCreatePullRequestTestWith(t, noOpenPullRequest, testRepository)
+}
+
+// cloneRemotes are the git remotes of a clone of the repository
+const cloneRemotes = "origin\tgit@github.example.com:owner/repo.git (fetch)\n"
+
+// setupCreatePullRequestTestWith function to set up the test with the given response of the open pull request query,
+// and the responses of the queries loading the repository in the order they are sent
+func setupCreatePullRequestTestWith(t *testing.T, openPullRequest string, repository ...string) *command_runner.FakeRunner {
+	t.Helper()
+
+	return setupCreatePullRequestTestIn(t, cloneRemotes, openPullRequest, repository...)
+}
+
+// setupCreatePullRequestTestIn function to set up the test like setupCreatePullRequestTestWith in a clone with the given git remotes
+func setupCreatePullRequestTestIn(
+	t *testing.T,
+	remotes string,
+	openPullRequest string,
+	repository ...string,
+) *command_runner.FakeRunner {
+	t.Helper()
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	configPath := filepath.Join(home, "config.json")
+	t.Setenv("LAZYGITHUB_CONFIG", configPath)
+	t.Setenv("LAZYGITHUB_STATE", filepath.Join(home, "state.json"))
+	// Every request must go to the host of the repository, here a GitHub Enterprise Server
+	t.Setenv("GH_HOST", "github.example.com")
+	config := `{"issueTrackers": [{"name": "jira", "pattern": "[A-Z]+-\\d+", "url": "https://example.atlassian.net/browse/{key}", "heading": "Jira Link"}]}`
+	if err := os.WriteFile(configPath, []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	previousIsInteractive := isInteractive
+	isInteractive = func() bool { return false }
+	t.Cleanup(func() { isInteractive = previousIsInteractive })
+
+	runner := command_runner.NewFakeRunner()
+	for _, response := range append(repository, openPullRequest) {
+		runner.Add(
+			command_runner.FakeResponse{Stdout: response},
+			"gh", "api", "graphql", "--hostname", "github.example.com", "--input", "-",
+		)
+	}
+	runner.
+		Add(
+			command_runner.FakeResponse{Stdout: `{"ref": "feature", "commit": "abc", "date": "2024-10-02 10:00:00 +0900"}` + "\n"},
+			"git", "for-each-ref", "refs/heads/", "--sort=-committerdate",
+			"--format={\"ref\": \"%(refname:short)\", \"commit\": \"%(objectname)\", \"date\": \"%(authordate:iso8601)\"}",
+		).
+		Add(
+			command_runner.FakeResponse{Stdout: home + "\n"},
+			"git", "rev-parse", "--show-toplevel",
+		).
+		Add(command_runner.FakeResponse{Stdout: remotes}, "git", "remote", "-v").
+		Add(
+			command_runner.FakeResponse{Stdout: "refs/heads/feature\x00origin/feature\x00origin\x00refs/heads/feature\x00\n"},
+			"git", "for-each-ref", branchStatusFormat, "refs/heads/feature",
+		).
+		Add(
+			// main was never fetched from the remote of the repository nor checked out,
+			// so the commits come from the compare API
+			command_runner.FakeResponse{Stdout: ""},
+			"git", "for-each-ref", "--format=%(refname)", "refs/remotes/origin/main",
+		).
+		Add(command_runner.FakeResponse{Stdout: ""}, "git", "for-each-ref", "--format=%(refname)", "refs/remotes/upstream/main").
+		Add(
+			command_runner.FakeResponse{Stdout: ""},
+			"git", "for-each-ref", branchStatusFormat, "refs/heads/main",
+		).
+		Add(
+			command_runner.FakeResponse{Stdout: `{"commits": [{"sha": "abc", "commit": {"message": "feat(ABC-123): add login\n\nThe login page", "author": {"name": "Alice"}}}]}`},
+			"gh", "api", "repos/owner/repo/compare/main...feature?per_page=100&page=1", "--hostname", "github.example.com",
+		)
+	t.Cleanup(command_runner.SetDefault(runner))
+
+	return runner
+}
+
+// branchStatusFormat is the format git_command.GetBranchStatus reads the upstream of a branch with
+const branchStatusFormat = "--format=%(refname)%00%(upstream:short)%00%(upstream:remotename)%00%(upstream:remoteref)%00%(upstream:track)"
+
+// graphqlCalls function to decode the GraphQL mutations that were sent
+func graphqlCalls(t *testing.T, runner *command_runner.FakeRunner) []graphqlRequest {
+	t.Helper()
+
+	requests := make([]graphqlRequest, 0)
+	for _, call := range runner.Calls() {
+		if call.CommandLine() != "gh api graphql --hostname github.example.com --input -" {
+			continue
+		}
+		var request graphqlRequest
+		if err := json.Unmarshal([]byte(call.Stdin), &request); err != nil {
+			t.Fatal(err)
+		}
+		if strings.HasPrefix(request.Query, "mutation") {
+			requests = append(requests, request)
+		}
+	}
+
+	return requests
+}
+
+func TestCreatePullRequest_Run(t *testing.T) {
+	draft := true
+
+	t.Run("create the pull request from the flags and the commits", func(t *testing.T) {
+		runner := setupCreatePullRequestTest(t)
+		runner.
+			Add(
+				command_runner.FakeResponse{Stdout: `{"data": {"createPullRequest": {"pullRequest": {"id": "PR_1", "number": 7, "url": "https://github.com/owner/repo/pull/7"}}}}`},
+				"gh", "api", "graphql", "--hostname", "github.example.com", "--input", "-",
+			).
+			Add(
+				command_runner.FakeResponse{Stdout: `{"data": {"requestReviews": {"clientMutationId": null}}}`},
+				"gh", "api", "graphql", "--hostname", "github.example.com", "--input", "-",
+			)
+
+		p := NewCreatePullRequest(CreatePullRequestOptions{
+			Repo:      "owner/repo",
+			Head:      "feature",
+			Reviewers: []string{"alice"},
+			Draft:     &draft,
+			Yes:       true,
+		})
+		if err := p.Run(context.Background()); err != nil {
+			t.Fatalf("CreatePullRequest.Run() error = %v", err)
+		}
+
+		requests := graphqlCalls(t, runner)
+		if len(requests) != 2 {
+			t.Fatalf("CreatePullRequest.Run() sent %d GraphQL requests, want 2", len(requests))
+		}
+
+		wantCreate := map[string]interface{}{
+			"repositoryId": "R_1",
+			"baseRefName":  "main",
+			"headRefName":  "feature",
+			"title":        "feat(ABC-123): add login",
+			"body":         "The login page\n\n### Jira Link\n\n[ABC-123](https://example.atlassian.net/browse/ABC-123)\n",
+			"draft":        true,
+		}
+		if !reflect.DeepEqual(requests[0].Variables.Input, wantCreate) {
+			t.Errorf("createPullRequest input = %v, want %v", requests[0].Variables.Input, wantCreate)
+		}
+
+		wantReviews := map[string]interface{}{
+			"pullRequestId": "PR_1",
+			"userIds":       []interface{}{"U_1"},
+			"teamIds":       []interface{}{},
+			"union":         true,
+		}
+		if !reflect.DeepEqual(requests[1].Variables.Input, wantReviews) {
+			t.Errorf("requestReviews input = %v, want %v", requests[1].Variables.Input, wantReviews)
+		}
+
+		store, err := state.NewStore()
+		if err != nil {
+			t.Fatal(err)
+		}
+		saved, err := store.Load()
+		if err != nil {
+			t.Fatal(err)
+		}
+		repository, ok := saved.Repositories["R_1"]
+		if !ok || !reflect.DeepEqual(repository.LatestReviewers(), []string{"alice"}) || repository.Name != "github.example.com/owner/repo" {
+			t.Errorf("saved reviewers = %v, want alice for github.example.com/owner/repo", repository)
+		}
+	})
+
+	t.Run("fail fast listing the missing values without a terminal", func(t *testing.T) {
+		runner := setupCreatePullRequestTest(t)
+
+		p := NewCreatePullRequest(CreatePullRequestOptions{Repo: "owner/repo", Head: "feature"})
+		err := p.Run(context.Background())
+		if err == nil || !strings.Contains(err.Error(), "base, title, body, reviewers, draft") {
+			t.Errorf("CreatePullRequest.Run() error = %v, want the missing values", err)
+		}
+		if calls := runner.Calls(); len(calls) != 0 {
+			t.Errorf("CreatePullRequest.Run() ran %v, want no commands", calls)
+		}
+	})
+
+	t.Run("report the error of the creation", func(t *testing.T) {
+		runner := setupCreatePullRequestTest(t)
+		runner.Add(
+			command_runner.FakeResponse{
+				Stdout:   `{"data": {"createPullRequest": null}, "errors": [{"type": "UNPROCESSABLE", "message": "A pull request already exists for owner:feature."}]}`,
+				Stderr:   "gh: A pull request already exists for owner:feature.",
+				ExitCode: 1,
+			},
+			"gh", "api", "graphql", "--hostname", "github.example.com", "--input", "-",
+		)
+
+		p := NewCreatePullRequest(CreatePullRequestOptions{
+			Repo:      "owner/repo",
+			Head:      "feature",
+			Reviewers: []string{"alice"},
+			Draft:     &draft,
+			Yes:       true,
+		})
+		err := p.Run(context.Background())
+
+		var graphqlErr *gh_command.GraphQLError
+		if !errors.As(err, &graphqlErr) || !strings.Contains(err.Error(), "A pull request already exists") {
+			t.Errorf("CreatePullRequest.Run() error = %v, want the GitHub API error", err)
+		}
+		if requests := graphqlCalls(t, runner); len(requests) != 1 {
+			t.Errorf("CreatePullRequest.Run() sent %d GraphQL requests, want 1", len(requests))
+		}
+	})
+
+	t.Run("stop loading when the context is cancelled", func(t *testing.T) {
+		runner := setupCreatePullRequestTest(t)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		p := NewCreatePullRequest(CreatePullRequestOptions{
+			Repo:      "owner/repo",
+			Head:      "feature",
+			Reviewers: []string{"alice"},
+			Draft:     &draft,
+			Yes:       true,
+		})
+		err := p.Run(ctx)
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("CreatePullRequest.Run() error = %v, want %v", err, context.Canceled)
+		}
+		if requests := graphqlCalls(t, runner); len(requests) != 0 {
+			t.Errorf("CreatePullRequest.Run() sent %d GraphQL mutations, want none", len(requests))
+		}
+	})
+
+	t.Run("request the code owners of the changed files", func(t *testing.T) {
+		runner := setupCreatePullRequestTest(t)
+		home := os.Getenv("HOME")
+		config := `{"reviewers": {"preselect": "none", "preselectCodeOwners": true}}`
+		if err := os.WriteFile(os.Getenv("LAZYGITHUB_CONFIG"), []byte(config), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(home, "CODEOWNERS"), []byte("* @bob\n/pkg/ @alice @org/backend\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		runner.
+			Add(
+				command_runner.FakeResponse{Stdout: "pkg/login.go\x00"},
+				"git", "diff", "--name-only", "-z", "main...feature",
+			).
+			Add(
+				command_runner.FakeResponse{Stdout: `{"data": {"createPullRequest": {"pullRequest": {"id": "PR_1", "number": 7, "url": "https://github.com/owner/repo/pull/7"}}}}`},
+				"gh", "api", "graphql", "--hostname", "github.example.com", "--input", "-",
+			).
+			Add(
+				command_runner.FakeResponse{Stdout: `{"data": {"requestReviews": {"clientMutationId": null}}}`},
+				"gh", "api", "graphql", "--hostname", "github.example.com", "--input", "-",
+			)
+
+		p := NewCreatePullRequest(CreatePullRequestOptions{
+			Repo:  "owner/repo",
+			Head:  "feature",
+			Draft: &draft,
+			Yes:   true,
+		})
+		if err := p.Run(context.Background()); err != nil {
+			t.Fatalf("CreatePullRequest.Run() error = %v", err)
+		}
+
+		requests := graphqlCalls(t, runner)
+		if len(requests) != 2 {
+			t.Fatalf("CreatePullRequest.Run() sent %d GraphQL requests, want 2", len(requests))
+		}
+		if got := requests[1].Variables.Input["userIds"]; !reflect.DeepEqual(got, []interface{}{"U_1"}) {
+			t.Errorf("requestReviews userIds = %v, want the code owner alice", got)
+		}
+	})
+
+	t.Run("request the teams given from the flags and remember them", func(t *testing.T) {
+		repository := strings.Replace(testRepository, `"__typename": "User"`, `"__typename": "Organization"`, 1)
+		runner := setupCreatePullRequestTestWith(t, noOpenPullRequest, repository)
+		runner.
+			Add(
+				command_runner.FakeResponse{Stdout: `[{"id": 1, "node_id": "T_1", "slug": "backend", "name": "Backend"}]`},
+				"gh", "api", "repos/owner/repo/teams?per_page=100&page=1", "--hostname", "github.example.com",
+			).
+			Add(
+				command_runner.FakeResponse{Stdout: `{"data": {"createPullRequest": {"pullRequest": {"id": "PR_1", "number": 7, "url": "https://github.com/owner/repo/pull/7"}}}}`},
+				"gh", "api", "graphql", "--hostname", "github.example.com", "--input", "-",
+			).
+			Add(
+				command_runner.FakeResponse{Stdout: `{"data": {"requestReviews": {"clientMutationId": null}}}`},
+				"gh", "api", "graphql", "--hostname", "github.example.com", "--input", "-",
+			)
+
+		p := NewCreatePullRequest(CreatePullRequestOptions{
+			Repo:      "owner/repo",
+			Head:      "feature",
+			Reviewers: []string{"owner/backend", "bob"},
+			Draft:     &draft,
+			Yes:       true,
+		})
+		if err := p.Run(context.Background()); err != nil {
+			t.Fatalf("CreatePullRequest.Run() error = %v", err)
+		}
+
+		requests := graphqlCalls(t, runner)
+		if len(requests) != 2 {
+			t.Fatalf("CreatePullRequest.Run() sent %d GraphQL requests, want 2", len(requests))
+		}
+		if got := requests[1].Variables.Input["userIds"]; !reflect.DeepEqual(got, []interface{}{"U_2"}) {
+			t.Errorf("requestReviews userIds = %v, want bob", got)
+		}
+		if got := requests[1].Variables.Input["teamIds"]; !reflect.DeepEqual(got, []interface{}{"T_1"}) {
+			t.Errorf("requestReviews teamIds = %v, want the backend team", got)
+		}
+
+		store, err := state.NewStore()
+		if err != nil {
+			t.Fatal(err)
+		}
+		saved, err := store.Load()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if latest := saved.Repositories["R_1"].LatestReviewers(); !reflect.DeepEqual(latest, []string{"bob", "owner/backend"}) {
+			t.Errorf("saved reviewers = %v, want [bob owner/backend]", latest)
+		}
+	})
+
+	t.Run("edit the open pull request of the head branch", func(t *testing.T) {
+		openPullRequest := `{"data": {"repository": {"pullRequests": {"nodes": [{
+			"id": "PR_1",
+			"number": 7,
+			"url": "https://github.com/owner/repo/pull/7",
+			"title": "Old title",
+			"body": "The body",
+			"state": "OPEN",
+			"isDraft": true,
+			"headRefName": "feature",
+			"baseRefName": "main",
+			"maintainerCanModify": false,
+			"author": {"login": "alice"},
+			"headRepositoryOwner": {"login": "owner"},
+			"labels": {"nodes": []},
+			"assignees": {"nodes": []},
+			"milestone": null,
+			"reviewRequests": {"nodes": [
+				{"requestedReviewer": {"__typename": "User", "login": "alice"}},
+				{"requestedReviewer": {"__typename": "User", "login": "outside-collaborator"}}
+			]}
+		}]}}}}`
+		runner := setupCreatePullRequestTestWith(t, openPullRequest, testRepository)
+		for _, response := range []string{
+			`{"data": {"updatePullRequest": {"clientMutationId": null}}}`,
+			`{"data": {"markPullRequestReadyForReview": {"clientMutationId": null}}}`,
+			`{"data": {"requestReviews": {"clientMutationId": null}}}`,
+		} {
+			runner.Add(
+				command_runner.FakeResponse{Stdout: response},
+				"gh", "api", "graphql", "--hostname", "github.example.com", "--input", "-",
+			)
+		}
+		runner.Add(
+			command_runner.FakeResponse{Stdout: "{}"},
+			"gh", "api", "repos/owner/repo/pulls/7/requested_reviewers", "--hostname", "github.example.com", "--method", "DELETE", "--input", "-",
+		)
+
+		ready := false
+		p := NewCreatePullRequest(CreatePullRequestOptions{
+			Repo:      "owner/repo",
+			Head:      "feature",
+			Title:     "New title",
+			Reviewers: []string{"bob"},
+			Draft:     &ready,
+			Yes:       true,
+		})
+		if err := p.Run(context.Background()); err != nil {
+			t.Fatalf("CreatePullRequest.Run() error = %v", err)
+		}
+
+		requests := graphqlCalls(t, runner)
+		if len(requests) != 3 {
+			t.Fatalf("CreatePullRequest.Run() sent %d GraphQL requests, want 3", len(requests))
+		}
+		wantUpdate := map[string]interface{}{"pullRequestId": "PR_1", "title": "New title"}
+		if !reflect.DeepEqual(requests[0].Variables.Input, wantUpdate) {
+			t.Errorf("updatePullRequest input = %v, want only the changed title %v", requests[0].Variables.Input, wantUpdate)
+		}
+		if !strings.Contains(requests[1].Query, "markPullRequestReadyForReview") {
+			t.Errorf("CreatePullRequest.Run() sent %s, want the pull request to be marked as ready", requests[1].Query)
+		}
+		if got := requests[2].Variables.Input["userIds"]; !reflect.DeepEqual(got, []interface{}{"U_2"}) {
+			t.Errorf("requestReviews userIds = %v, want only the added bob", got)
+		}
+
+		removed := false
+		for _, call := range runner.Calls() {
+			if strings.HasPrefix(call.CommandLine(), "gh api repos/owner/repo/pulls/7/requested_reviewers") {
+				removed = call.Stdin == `{"reviewers":["alice"],"team_reviewers":[]}`
+			}
+		}
+		if !removed {
+			t.Errorf("CreatePullRequest.Run() did not withdraw only the review request of alice, not the reviewer who is not assignable")
+		}
+	})
+
+	t.Run("keep the base of the open pull request when none is given", func(t *testing.T) {
+		// The open pull request is stacked on feature-1 rather than on the default branch
+		openPullRequest := `{"data": {"repository": {"pullRequests": {"nodes": [{
+			"id": "PR_1",
+			"number": 7,
+			"url": "https://github.com/owner/repo/pull/7",
+			"title": "Old title",
+			"body": "The body",
+			"state": "OPEN",
+			"isDraft": false,
+			"headRefName": "feature",
+			"baseRefName": "feature-1",
+			"maintainerCanModify": false,
+			"author": {"login": "alice"},
+			"headRepositoryOwner": {"login": "owner"},
+			"labels": {"nodes": []},
+			"assignees": {"nodes": []},
+			"milestone": null,
+			"reviewRequests": {"nodes": []}
+		}]}}}}`
+		runner := setupCreatePullRequestTestWith(t, openPullRequest, testRepository)
+		runner.Add(
+			command_runner.FakeResponse{Stdout: `{"data": {"updatePullRequest": {"clientMutationId": null}}}`},
+			"gh", "api", "graphql", "--hostname", "github.example.com", "--input", "-",
+		)
+
+		p := NewCreatePullRequest(CreatePullRequestOptions{
+			Repo:      "owner/repo",
+			Head:      "feature",
+			Title:     "New title",
+			Reviewers: []string{},
+			Yes:       true,
+		})
+		if err := p.Run(context.Background()); err != nil {
+			t.Fatalf("CreatePullRequest.Run() error = %v", err)
+		}
+
+		requests := graphqlCalls(t, runner)
+		if len(requests) != 1 {
+			t.Fatalf("CreatePullRequest.Run() sent %d GraphQL requests, want 1", len(requests))
+		}
+		wantUpdate := map[string]interface{}{"pullRequestId": "PR_1", "title": "New title"}
+		if !reflect.DeepEqual(requests[0].Variables.Input, wantUpdate) {
+			t.Errorf("updatePullRequest input = %v, want the base left on feature-1 %v", requests[0].Variables.Input, wantUpdate)
+		}
+		if p.pullRequest.BaseRefName != "feature-1" {
+			t.Errorf("CreatePullRequest.Run() base = %q, want feature-1", p.pullRequest.BaseRefName)
+		}
+	})
+
+	t.Run("open the pull request from the fork of origin against upstream", func(t *testing.T) {
+		// The branch of someone else's fork with the same name is not the head branch
+		openPullRequest := `{"data": {"repository": {"pullRequests": {"nodes": [{
+			"id": "PR_9",
+			"number": 9,
+			"headRefName": "feature",
+			"headRepositoryOwner": {"login": "someone"}
+		}]}}}}`
+		runner := setupCreatePullRequestTestIn(
+			t,
+			"origin\tgit@github.example.com:fork/repo.git (fetch)\nupstream\tgit@github.example.com:owner/repo.git (fetch)\n",
+			openPullRequest,
+			testRepository,
+		)
+		runner.
+			Add(
+				command_runner.FakeResponse{Stdout: `{"commits": [{"sha": "abc", "commit": {"message": "fix: typo", "author": {"name": "Alice"}}}]}`},
+				"gh", "api", "repos/owner/repo/compare/main...fork:feature?per_page=100&page=1", "--hostname", "github.example.com",
+			).
+			Add(
+				command_runner.FakeResponse{Stdout: `{"data": {"createPullRequest": {"pullRequest": {"id": "PR_1", "number": 7, "url": "https://github.com/owner/repo/pull/7"}}}}`},
+				"gh", "api", "graphql", "--hostname", "github.example.com", "--input", "-",
+			)
+
+		maintainerCanModify := false
+		p := NewCreatePullRequest(CreatePullRequestOptions{
+			Repo:                "owner/repo",
+			Head:                "feature",
+			Reviewers:           []string{},
+			MaintainerCanModify: &maintainerCanModify,
+			Yes:                 true,
+		})
+		if err := p.Run(context.Background()); err != nil {
+			t.Fatalf("CreatePullRequest.Run() error = %v", err)
+		}
+
+		requests := graphqlCalls(t, runner)
+		if len(requests) != 1 {
+			t.Fatalf("CreatePullRequest.Run() sent %d GraphQL requests, want 1", len(requests))
+		}
+		input := requests[0].Variables.Input
+		if input["headRefName"] != "fork:feature" || input["maintainerCanModify"] != false || input["title"] != "fix: typo" {
+			t.Errorf("createPullRequest input = %v, want the fork:feature head without maintainer edits", input)
+		}
+	})
+}
+
+func TestCreatePullRequest_dryRun(t *testing.T) {
+	runner := setupCreatePullRequestTest(t)
+
+	// The JSON document is the only output on stdout
+	stdout := os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	draft := false
+	p := NewCreatePullRequest(CreatePullRequestOptions{
+		Repo:      "owner/repo",
+		Head:      "feature",
+		Reviewers: []string{"alice"},
+		Draft:     &draft,
+		DryRun:    true,
+		Yes:       true,
+	})
+	errRun := p.Run(context.Background())
+	w.Close()
+	os.Stdout = stdout
+	if errRun != nil {
+		t.Fatalf("CreatePullRequest.Run() error = %v", errRun)
+	}
+
+	var got dryRunRequest
+	if err := json.NewDecoder(r).Decode(&got); err != nil {
+		t.Fatalf("CreatePullRequest.Run() did not print a JSON document: %v", err)
+	}
+	want := dryRunRequest{
+		Action:        "create",
+		Repository:    dryRunRepository{ID: "R_1", Host: "github.example.com", Owner: "owner", Name: "repo"},
+		Head:          "feature",
+		Base:          "main",
+		Title:         "feat(ABC-123): add login",
+		Body:          "The login page\n\n### Jira Link\n\n[ABC-123](https://example.atlassian.net/browse/ABC-123)\n",
+		Reviewers:     []string{"alice"},
+		TeamReviewers: []string{},
+		Labels:        []string{},
+		Assignees:     []string{},
+		Projects:      []string{},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CreatePullRequest.Run() printed %+v, want %+v", got, want)
+	}
+
+	if requests := graphqlCalls(t, runner); len(requests) != 0 {
+		t.Errorf("CreatePullRequest.Run() sent %d GraphQL mutations on a dry run, want none", len(requests))
+	}
+	if _, err := os.Stat(os.Getenv("LAZYGITHUB_STATE")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("CreatePullRequest.Run() saved the choices on a dry run, stat error = %v", err)
+	}
+}
+
+func TestCreatePullRequest_metadata(t *testing.T) {
+	runner := command_runner.NewFakeRunner().
+		Add(
+			command_runner.FakeResponse{Stdout: `{"data": {"createPullRequest": {"pullRequest": {"id": "PR_1", "number": 7, "url": "https://github.com/owner/repo/pull/7"}}}}`},
+			"gh", "api", "graphql", "--hostname", "github.example.com", "--input", "-",
+		).
+		Add(
+			command_runner.FakeResponse{Stdout: `{"data": {"updatePullRequest": {"clientMutationId": null}}}`},
+			"gh", "api", "graphql", "--hostname", "github.example.com", "--input", "-",
+		).
+		Add(
+			command_runner.FakeResponse{Stdout: `{"data": {"addProjectV2ItemById": {"item": {"id": "I_1"}}}}`},
+			"gh", "api", "graphql", "--hostname", "github.example.com", "--input", "-",
+		)
+	defer command_runner.SetDefault(runner)()
+
+	p := &CreatePullRequest{
+		repoId:          "R_1",
+		repoHost:        "github.example.com",
+		assignableUsers: []gh_command.RepoAssignableUser{{ID: "U_1", Login: "alice"}, {ID: "U_2", Login: "bob"}},
+		repoLabels:      []gh_command.RepoLabel{{ID: "L_1", Name: "bug"}, {ID: "L_2", Name: "feature"}},
+		repoMilestones:  []gh_command.RepoMilestone{{ID: "M_1", Title: "v1.0"}},
+		repoProjects:    []gh_command.RepoProject{{ID: "P_1", Title: "Roadmap"}},
+		latestMetadata: &state.MetadataSelection{
+			Labels:    []string{"feature", "deleted"},
+			Assignees: []string{"bob", "gone"},
+			Milestone: "v1.0",
+			Projects:  []string{"Roadmap", "Old"},
+		},
+	}
+	p.preselectMetadata()
+	if !reflect.DeepEqual(p.labels, []string{"feature"}) || !reflect.DeepEqual(p.assignees, []string{"bob"}) ||
+		p.milestone != "v1.0" || !reflect.DeepEqual(p.projects, []string{"Roadmap"}) {
+		t.Errorf(
+			"CreatePullRequest.preselectMetadata() = %v %v %q %v, want the latest choices that still exist",
+			p.labels,
+			p.assignees,
+			p.milestone,
+			p.projects,
+		)
+	}
+
+	if _, err := p.submit(context.Background()); err != nil {
+		t.Fatalf("CreatePullRequest.submit() error = %v", err)
+	}
+
+	requests := graphqlCalls(t, runner)
+	if len(requests) != 3 {
+		t.Fatalf("CreatePullRequest.submit() sent %d GraphQL requests, want 3", len(requests))
+	}
+	wantUpdate := map[string]interface{}{
+		"pullRequestId": "PR_1",
+		"labelIds":      []interface{}{"L_2"},
+		"assigneeIds":   []interface{}{"U_2"},
+		"milestoneId":   "M_1",
+	}
+	if !reflect.DeepEqual(requests[1].Variables.Input, wantUpdate) {
+		t.Errorf("updatePullRequest input = %v, want %v", requests[1].Variables.Input, wantUpdate)
+	}
+	wantProject := map[string]interface{}{"projectId": "P_1", "contentId": "PR_1"}
+	if !reflect.DeepEqual(requests[2].Variables.Input, wantProject) {
+		t.Errorf("addProjectV2ItemById input = %v, want %v", requests[2].Variables.Input, wantProject)
+	}
+}
+
+func TestCreatePullRequest_pushHeadBranch(t *testing.T) {
+	yes := true
+	tests := []struct {
+		name   string
+		status string
+		fork   bool
+		// yes gives --yes instead of --push
+		yes      bool
+		wantPush []string
+		wantErr  bool
+	}{
+		{
+			name:     "push a branch that was never pushed to the remote of the repository",
+			status:   "refs/heads/feature\x00\x00\x00\x00\n",
+			wantPush: []string{"push", "--set-upstream", "upstream", "refs/heads/feature:refs/heads/feature"},
+		},
+		{
+			name:     "push a branch that was never pushed to the fork",
+			status:   "refs/heads/feature\x00\x00\x00\x00\n",
+			fork:     true,
+			wantPush: []string{"push", "--set-upstream", "origin", "refs/heads/feature:refs/heads/feature"},
+		},
+		{
+			name:     "push a branch tracking another name to the branch with its own name",
+			status:   "refs/heads/feature\x00origin/main\x00origin\x00refs/heads/main\x00[ahead 2, behind 1]\n",
+			yes:      true,
+			wantPush: []string{"push", "--set-upstream", "upstream", "refs/heads/feature:refs/heads/feature"},
+		},
+		{
+			name:     "push the new commits",
+			status:   "refs/heads/feature\x00origin/feature\x00origin\x00refs/heads/feature\x00[ahead 2]\n",
+			wantPush: []string{"push", "origin", "refs/heads/feature:refs/heads/feature"},
+		},
+		{
+			name:     "force push with lease after a rebase",
+			status:   "refs/heads/feature\x00origin/feature\x00origin\x00refs/heads/feature\x00[ahead 2, behind 1]\n",
+			wantPush: []string{"push", "--force-with-lease", "origin", "refs/heads/feature:refs/heads/feature"},
+		},
+		{
+			name:     "push the new commits with --yes",
+			status:   "refs/heads/feature\x00origin/feature\x00origin\x00refs/heads/feature\x00[ahead 2]\n",
+			yes:      true,
+			wantPush: []string{"push", "origin", "refs/heads/feature:refs/heads/feature"},
+		},
+		{
+			name:    "refuse to force push with only --yes",
+			status:  "refs/heads/feature\x00origin/feature\x00origin\x00refs/heads/feature\x00[ahead 2, behind 1]\n",
+			yes:     true,
+			wantErr: true,
+		},
+		{
+			name:   "leave a branch that is behind alone",
+			status: "refs/heads/feature\x00origin/feature\x00origin\x00refs/heads/feature\x00[behind 1]\n",
+		},
+		{
+			name:   "leave a branch that only exists on the remote alone",
+			status: "",
+		},
+		{
+			name:    "ask for the push flag without a terminal",
+			status:  "refs/heads/feature\x00origin/feature\x00origin\x00refs/heads/feature\x00[ahead 2]\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := command_runner.NewFakeRunner().
+				Add(
+					command_runner.FakeResponse{Stdout: tt.status},
+					"git", "for-each-ref", branchStatusFormat, "refs/heads/feature",
+				).
+				Add(
+					command_runner.FakeResponse{Stdout: "origin\tgit@github.example.com:fork/repo.git (fetch)\n" +
+						"upstream\tgit@github.example.com:owner/repo.git (fetch)\n"},
+					"git", "remote", "-v",
+				)
+			if tt.wantPush != nil {
+				runner.Add(command_runner.FakeResponse{}, "git", tt.wantPush...)
+			}
+			defer command_runner.SetDefault(runner)()
+
+			options := CreatePullRequestOptions{Push: &yes}
+			if tt.yes {
+				options = CreatePullRequestOptions{Yes: true}
+			} else if tt.wantErr {
+				// Without --push the user would have to be asked, which needs a terminal
+				options.Push = nil
+			}
+			p := &CreatePullRequest{
+				options:    options,
+				repoHost:   "github.example.com",
+				repoOwner:  "owner",
+				repoName:   "repo",
+				headBranch: "feature",
+			}
+			if tt.fork {
+				p.headRepoOwner, p.headRepoName = "fork", "repo"
+			}
+			err := p.pushHeadBranch(context.Background(), false)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CreatePullRequest.pushHeadBranch() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			pushed := false
+			for _, call := range runner.Calls() {
+				if call.Name == "git" && call.Args[0] == "push" {
+					pushed = true
+				}
+			}
+			if pushed != (tt.wantPush != nil) {
+				t.Errorf("CreatePullRequest.pushHeadBranch() pushed = %v, want %v", pushed, tt.wantPush != nil)
+			}
+		})
+	}
+}
+
+func TestCreatePullRequest_loadCommits(t *testing.T) {
+	forkClone := "origin\tgit@github.example.com:fork/repo.git (fetch)\nupstream\tgit@github.example.com:owner/repo.git (fetch)\n"
+	tests := []struct {
+		name    string
+		remotes string
+		fork    bool
+		// fetched is the remote tracking branch of main that was fetched, if any
+		fetched string
+		// localBase is true when main is a local branch
+		localBase bool
+		// wantBase is the ref the commits are listed from, empty when they come from the compare API
+		wantBase string
+	}{
+		{
+			name:     "compare with the fetched base branch",
+			remotes:  cloneRemotes,
+			fetched:  "refs/remotes/origin/main",
+			wantBase: "refs/remotes/origin/main",
+		},
+		{
+			name:     "compare a fork with the base branch of upstream",
+			remotes:  forkClone,
+			fork:     true,
+			fetched:  "refs/remotes/upstream/main",
+			wantBase: "refs/remotes/upstream/main",
+		},
+		{
+			name:      "compare with the local base branch that was never fetched",
+			remotes:   cloneRemotes,
+			localBase: true,
+			wantBase:  "main",
+		},
+		{
+			name:    "ask GitHub when the base branch is not available locally",
+			remotes: cloneRemotes,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			localBase := ""
+			if tt.localBase {
+				localBase = "refs/heads/main\x00\x00\x00\x00\n"
+			}
+			message := "feat: add login\n\nSigned-off-by: Alice <alice@example.com>"
+			runner := command_runner.NewFakeRunner().
+				Add(
+					command_runner.FakeResponse{Stdout: "refs/heads/feature\x00\x00\x00\x00\n"},
+					"git", "for-each-ref", branchStatusFormat, "refs/heads/feature",
+				).
+				Add(command_runner.FakeResponse{Stdout: tt.remotes}, "git", "remote", "-v").
+				Add(command_runner.FakeResponse{Stdout: tt.fetched + "\n"}, "git", "for-each-ref", "--format=%(refname)", "refs/remotes/origin/main").
+				Add(command_runner.FakeResponse{Stdout: tt.fetched + "\n"}, "git", "for-each-ref", "--format=%(refname)", "refs/remotes/upstream/main").
+				Add(command_runner.FakeResponse{Stdout: localBase}, "git", "for-each-ref", branchStatusFormat, "refs/heads/main").
+				Add(command_runner.FakeResponse{Stdout: "base\n"}, "git", "merge-base", tt.wantBase, "feature").
+				Add(
+					command_runner.FakeResponse{Stdout: "abc\x1fAlice\x1f" + message + "\n\x00"},
+					"git", "log", "--reverse", "-z", "--format=%H%x1f%an%x1f%B", "base..feature",
+				).
+				Add(
+					command_runner.FakeResponse{Stdout: `{"commits": [{"sha": "abc", "commit": {"message": "feat: add login\n\nSigned-off-by: Alice <alice@example.com>", "author": {"name": "Alice"}}}]}`},
+					"gh", "api", "repos/owner/repo/compare/main...feature?per_page=100&page=1", "--hostname", "github.example.com",
+				)
+			defer command_runner.SetDefault(runner)()
+
+			p := &CreatePullRequest{
+				repoHost:   "github.example.com",
+				repoOwner:  "owner",
+				repoName:   "repo",
+				baseBranch: "main",
+				headBranch: "feature",
+			}
+			if tt.fork {
+				p.headRepoOwner, p.headRepoName = "fork", "repo"
+			}
+			got, err := p.loadCommits(context.Background())
+			if err != nil {
+				t.Fatalf("CreatePullRequest.loadCommits() error = %v", err)
+			}
+			want := []gh_command.Commit{{
+				Sha:      "abc",
+				Message:  message,
+				Author:   "Alice",
+				Trailers: []git_command.Trailer{{Key: "Signed-off-by", Value: "Alice <alice@example.com>"}},
+			}}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("CreatePullRequest.loadCommits() = %v, want %v", got, want)
+			}
+
+			askedGitHub := false
+			for _, call := range runner.Calls() {
+				if call.Name == "gh" {
+					askedGitHub = true
+				}
+			}
+			if askedGitHub != (tt.wantBase == "") {
+				t.Errorf("CreatePullRequest.loadCommits() asked GitHub = %v, want %v", askedGitHub, tt.wantBase == "")
+			}
+		})
+	}
+}
@@ -0,0 +1,218 @@
+package cli_prompt
+
+import (
+	"log"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/huh"
+	"github.com/coding-for-fun-org/lazygithub/pkg/config"
+	"github.com/coding-for-fun-org/lazygithub/pkg/gh_command"
+)
+
+// conventionalHeaderPattern matches the "type(scope)!: subject" header of a Conventional Commit
+var conventionalHeaderPattern = regexp.MustCompile(`^([A-Za-z]+)(?:\(([^()]*)\))?(!)?:\s+(.+)$`)
+
+// conventionalHeader struct to represent the header of a Conventional Commit
+type conventionalHeader struct {
+	kind     string
+	scope    string
+	breaking bool
+	subject  string
+}
+
+// parseConventionalHeader function to parse the summary of a commit, false when it is not a Conventional Commit
+func parseConventionalHeader(summary string) (conventionalHeader, bool) {
+	match := conventionalHeaderPattern.FindStringSubmatch(strings.TrimSpace(summary))
+	if match == nil {
+		return conventionalHeader{}, false
+	}
+
+	return conventionalHeader{
+		kind:     strings.ToLower(match[1]),
+		scope:    strings.TrimSpace(match[2]),
+		breaking: match[3] == "!",
+		subject:  strings.TrimSpace(match[4]),
+	}, true
+}
+
+// conventionalTypeOrder breaks the tie between types with as many commits, the ones that matter most to a reader first
+var conventionalTypeOrder = []string{"feat", "fix", "perf", "refactor", "revert", "docs", "test", "build", "ci", "style", "chore"}
+
+// conventionalTypeRank function to get the position of a type in conventionalTypeOrder, unknown types come last
+func conventionalTypeRank(kind string) int {
+	if rank := slices.Index(conventionalTypeOrder, kind); rank >= 0 {
+		return rank
+	}
+
+	return len(conventionalTypeOrder)
+}
+
+// conventionalTitle function to suggest a title from the Conventional Commit headers of the commits.
+// The type is the one most commits have, the scope is kept when every commit shares it,
+// the title is marked as breaking when any commit is, and the subjects of the commits of that type are joined.
+// It returns an empty string when no commit follows the convention.
+func conventionalTitle(commits []gh_command.Commit) string {
+	headers := make([]conventionalHeader, 0, len(commits))
+	for _, commit := range commits {
+		summary, _ := splitCommitSummaryAndDescription(commit.Message)
+		if header, ok := parseConventionalHeader(summary); ok {
+			headers = append(headers, header)
+		}
+	}
+	if len(headers) == 0 {
+		return ""
+	}
+
+	counts := make(map[string]int)
+	for _, header := range headers {
+		counts[header.kind]++
+	}
+	dominant := ""
+	for kind, count := range counts {
+		switch {
+		case dominant == "", count > counts[dominant]:
+			dominant = kind
+		case count == counts[dominant]:
+			rank, dominantRank := conventionalTypeRank(kind), conventionalTypeRank(dominant)
+			if rank < dominantRank || (rank == dominantRank && kind < dominant) {
+				dominant = kind
+			}
+		}
+	}
+
+	scope := headers[0].scope
+	breaking := false
+	subjects := make([]string, 0)
+	for _, header := range headers {
+		if header.scope != scope {
+			scope = ""
+		}
+		breaking = breaking || header.breaking
+		if header.kind == dominant {
+			subjects = concatenateAndRemoveDuplicates(subjects, []string{header.subject})
+		}
+	}
+
+	title := dominant
+	if scope != "" {
+		title += "(" + scope + ")"
+	}
+	if breaking {
+		title += "!"
+	}
+
+	return title + ": " + strings.Join(subjects, ", ")
+}
+
+// capitalize function to upper case the first letter of a text
+func capitalize(text string) string {
+	first, size := utf8.DecodeRuneInString(text)
+	if first == utf8.RuneError {
+		return text
+	}
+
+	return string(unicode.ToUpper(first)) + text[size:]
+}
+
+// branchIssueKeyPattern matches the issue keys kept in a title made from a branch name when no rule finds them,
+// like "ABC-123" or "#123"
+var branchIssueKeyPattern = regexp.MustCompile(`[A-Z][A-Z0-9]+-\d+|#\d+`)
+
+// branchTitle function to suggest a title from the name of a branch, like "ABC-123 Add login" for "feature/ABC-123-add-login".
+// The prefix before the last "/" is dropped, the issue keys the rules or branchIssueKeyPattern find are kept as they are and put first,
+// and the rest is turned into words.
+func branchTitle(branch string, rules []config.IssueTrackerRule) string {
+	name := branch[strings.LastIndex(branch, "/")+1:]
+
+	keys := make([]string, 0)
+	for _, rule := range rules {
+		re, err := rule.Compile()
+		if err != nil {
+			log.Printf("Failed to extract issue keys from the branch name: %s", err)
+			continue
+		}
+
+		ruleKeys := make([]string, 0)
+		matches := re.FindAllStringSubmatchIndex(name, -1)
+		// The matches are cut out from the last one, so that the indices of the others stay valid
+		for i := len(matches) - 1; i >= 0; i-- {
+			key, _ := rule.Link(re, name, matches[i])
+			ruleKeys = append([]string{key}, ruleKeys...)
+			name = name[:matches[i][0]] + " " + name[matches[i][1]:]
+		}
+		keys = concatenateAndRemoveDuplicates(keys, ruleKeys)
+	}
+
+	// The keys no rule knows about, like without any issue tracker configured, are kept as well
+	fallbackKeys := make([]string, 0)
+	matches := branchIssueKeyPattern.FindAllStringIndex(name, -1)
+	for i := len(matches) - 1; i >= 0; i-- {
+		fallbackKeys = append([]string{name[matches[i][0]:matches[i][1]]}, fallbackKeys...)
+		name = name[:matches[i][0]] + " " + name[matches[i][1]:]
+	}
+	keys = concatenateAndRemoveDuplicates(keys, fallbackKeys)
+
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return r == '-' || r == '_' || r == '.' || unicode.IsSpace(r)
+	})
+	if len(words) > 0 {
+		keys = append(keys, capitalize(strings.Join(words, " ")))
+	}
+
+	return strings.Join(keys, " ")
+}
+
+// titleCandidates function to list the suggested titles without duplicates, the best first:
+// the summary of a single commit, the title made from the Conventional Commits of several,
+// and the one made from the branch name when branchFallback is true
+func titleCandidates(
+	commitTitle string,
+	commits []gh_command.Commit,
+	branch string,
+	rules []config.IssueTrackerRule,
+	branchFallback bool,
+) []string {
+	candidates := []string{commitTitle}
+	if len(commits) > 1 {
+		candidates = append(candidates, conventionalTitle(commits))
+	}
+	if branchFallback {
+		candidates = append(candidates, branchTitle(branch, rules))
+	}
+
+	titles := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		if candidate != "" {
+			titles = concatenateAndRemoveDuplicates(titles, []string{candidate})
+		}
+	}
+
+	return titles
+}
+
+// titleForm method to create a form for choosing one of the suggested titles to start from.
+// It returns nil when there is nothing to choose from.
+func (p *CreatePullRequest) titleForm() *huh.Form {
+	if len(p.titleCandidates) < 2 || !p.promptsTitle() || p.existing != nil {
+		return nil
+	}
+
+	options := make([]huh.Option[string], 0, len(p.titleCandidates))
+	for _, candidate := range p.titleCandidates {
+		options = append(options, huh.NewOption(candidate, candidate))
+	}
+
+	return huh.NewForm(
+		huh.NewGroup(
+			huh.NewSelect[string]().
+				Title("Select a title to start from").
+				Description("It can still be edited afterwards").
+				Options(options...).
+				Value(&p.title),
+		),
+	)
+}
@@ -0,0 +1,118 @@
+package cli_prompt
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/coding-for-fun-org/lazygithub/pkg/config"
+	"github.com/coding-for-fun-org/lazygithub/pkg/gh_command"
+)
+
+// commitsOf function to make commits out of messages
+func commitsOf(messages ...string) []gh_command.Commit {
+	commits := make([]gh_command.Commit, 0, len(messages))
+	for _, message := range messages {
+		commits = append(commits, gh_command.Commit{Message: message})
+	}
+
+	return commits
+}
+
+func Test_conventionalTitle(t *testing.T) {
+	tests := []struct {
+		name    string
+		commits []gh_command.Commit
+		want    string
+	}{
+		{
+			name:    "dominant type and common scope",
+			commits: commitsOf("feat(auth): add login\n\nThe page", "fix(auth): typo", "feat(auth): add logout"),
+			want:    "feat(auth): add login, add logout",
+		},
+		{
+			name:    "different scopes are left out",
+			commits: commitsOf("fix(api): handle timeouts", "fix(ui): show the error"),
+			want:    "fix: handle timeouts, show the error",
+		},
+		{
+			name:    "ties go to the type that matters most and breaking changes are kept",
+			commits: commitsOf("chore: bump deps", "Feat!: drop the v1 API", "Merge branch 'main'"),
+			want:    "feat!: drop the v1 API",
+		},
+		{
+			name:    "no conventional commits",
+			commits: commitsOf("Add login", "Fix typo"),
+			want:    "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := conventionalTitle(tt.commits); got != tt.want {
+				t.Errorf("conventionalTitle() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_branchTitle(t *testing.T) {
+	tests := []struct {
+		name   string
+		branch string
+		rules  []config.IssueTrackerRule
+		want   string
+	}{
+		{
+			name:   "issue key kept and the rest humanized",
+			branch: "feature/ABC-123-add-login",
+			rules:  []config.IssueTrackerRule{jiraRule},
+			want:   "ABC-123 Add login",
+		},
+		{
+			name:   "issue key in the middle",
+			branch: "user/alice/fix_ABC-7_and_DEF-8.typos",
+			rules:  []config.IssueTrackerRule{jiraRule},
+			want:   "ABC-7 DEF-8 Fix and typos",
+		},
+		{
+			name:   "without rules",
+			branch: "add-login",
+			want:   "Add login",
+		},
+		{
+			name:   "issue keys kept without rules",
+			branch: "feature/ABC-123-add-login",
+			want:   "ABC-123 Add login",
+		},
+		{
+			name:   "issue number kept without rules",
+			branch: "fix-#42-typo",
+			want:   "#42 Fix typo",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := branchTitle(tt.branch, tt.rules); got != tt.want {
+				t.Errorf("branchTitle() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_titleCandidates(t *testing.T) {
+	rules := []config.IssueTrackerRule{jiraRule}
+
+	got := titleCandidates("", commitsOf("feat: add login", "feat: add logout"), "feature/ABC-123-add-login", rules, true)
+	if want := []string{"feat: add login, add logout", "ABC-123 Add login"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("titleCandidates() = %v, want %v", got, want)
+	}
+
+	got = titleCandidates("Add login", commitsOf("Add login"), "add-login", rules, true)
+	if want := []string{"Add login"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("titleCandidates() = %v, want the duplicate branch title left out %v", got, want)
+	}
+
+	got = titleCandidates("", commitsOf("Add login", "Fix typo"), "feature/add-login", rules, false)
+	if len(got) != 0 {
+		t.Errorf("titleCandidates() = %v, want none without the branch fallback", got)
+	}
+}
@@ -1,31 +1,93 @@
 package cli_prompt
 
 import (
-	"encoding/csv"
 	"fmt"
 	"log"
-	"os"
-	"path/filepath"
-	"regexp"
+	"slices"
+	"sort"
 	"strings"
+	"text/template"
 
+	"github.com/charmbracelet/huh"
+	"github.com/coding-for-fun-org/lazygithub/pkg/config"
 	"github.com/coding-for-fun-org/lazygithub/pkg/gh_command"
+	"github.com/coding-for-fun-org/lazygithub/pkg/state"
 )
 
-// extractPatterns function extracts all occurrences of the pattern [A-Z]{1,}-\d{1,} from the input string.
-func extractPatterns(input string) ([]string, error) {
-	// Define the regular expression
-	pattern := `[A-Z]+-\d+`
+// issueLink struct to represent a link to an issue found in a commit
+type issueLink struct {
+	key string
+	url string
+}
 
+// extractIssueLinks function extracts the links of all issue keys the rule finds in the input string.
+func extractIssueLinks(rule config.IssueTrackerRule, input string) ([]issueLink, error) {
 	// Compile the regular expression
-	re, err := regexp.Compile(pattern)
+	re, err := rule.Compile()
 	if err != nil {
 		return nil, err
 	}
 
 	// Find all matches
-	matches := re.FindAllString(input, -1)
-	return matches, nil
+	links := make([]issueLink, 0)
+	for _, match := range re.FindAllStringSubmatchIndex(input, -1) {
+		key, url := rule.Link(re, input, match)
+		links = append(links, issueLink{key: key, url: url})
+	}
+
+	return links, nil
+}
+
+// buildIssueLinkSections function to build the markdown sections that link the issues found in the inputs.
+// Rules sharing the same heading are listed in the same section, and sections without links are left out.
+func buildIssueLinkSections(rules []config.IssueTrackerRule, inputs []string) string {
+	headings := make([]string, 0)
+	linksByHeading := make(map[string][]string)
+
+	for _, rule := range rules {
+		if _, exists := linksByHeading[rule.Heading]; !exists {
+			headings = append(headings, rule.Heading)
+			linksByHeading[rule.Heading] = []string{}
+		}
+
+		keys := make([]string, 0)
+		urlByKey := make(map[string]string)
+		for _, input := range inputs {
+			links, err := extractIssueLinks(rule, input)
+			if err != nil {
+				log.Printf("Failed to extract issue links: %s", err)
+				break
+			}
+
+			linkKeys := make([]string, 0, len(links))
+			for _, link := range links {
+				linkKeys = append(linkKeys, link.key)
+				if _, exists := urlByKey[link.key]; !exists {
+					urlByKey[link.key] = link.url
+				}
+			}
+			keys = concatenateAndRemoveDuplicates(keys, linkKeys)
+		}
+
+		for _, key := range keys {
+			line := key
+			if urlByKey[key] != "" {
+				line = fmt.Sprintf("[%s](%s)", key, urlByKey[key])
+			}
+			linksByHeading[rule.Heading] = append(linksByHeading[rule.Heading], line+"\n")
+		}
+	}
+
+	sections := make([]string, 0, len(headings))
+	for _, heading := range headings {
+		links := concatenateAndRemoveDuplicates(linksByHeading[heading], nil)
+		if len(links) == 0 {
+			continue
+		}
+		sections = append(sections, "### "+heading+"\n\n"+strings.Join(links, ""))
+	}
+
+	return strings.Join(sections, "\n")
 }
 
 // concatenateAndRemoveDuplicates function to concatenate two slices and remove duplicates
@@ -66,160 +128,219 @@ func splitCommitSummaryAndDescription(commitMessage string) (string, string) {
 	return commitMessage, ""
 }
 
-func getPrePopulatedTitleAndBody(
-	commits []gh_command.Commit,
-) (string, string) {
-	if len(commits) == 1 {
-		commitTitle, commitBody := splitCommitSummaryAndDescription(commits[0].Message)
-		issueNumbersFromTitle, _ := extractPatterns(commitTitle)
-		issueNumbersFromBody, _ := extractPatterns(commitBody)
-		issueNumbers := concatenateAndRemoveDuplicates(
-			issueNumbersFromTitle,
-			issueNumbersFromBody,
-		)
-		commitBody = commitBody + "\n\n### Jira Link\n\n"
-		for _, issueNumber := range issueNumbers {
-			commitBody = commitBody + fmt.Sprintf(
-				"[%s](https://keends.atlassian.net/browse/%s)\n",
-				issueNumber,
-				issueNumber,
-			)
-		}
-		return commitTitle, commitBody
-	}
-
-	linkBody := "### Jira Link\n\n"
-	commitFullBody := ""
-	for _, commit := range commits {
-		commitTitle, commitBody := splitCommitSummaryAndDescription(commit.Message)
-		issueNumbersFromTitle, _ := extractPatterns(commitTitle)
-		issueNumbersFromBody, _ := extractPatterns(commitBody)
-		issueNumbers := concatenateAndRemoveDuplicates(
-			issueNumbersFromTitle,
-			issueNumbersFromBody,
-		)
-		for _, issueNumber := range issueNumbers {
-			linkBody = linkBody + fmt.Sprintf(
-				"[%s](https://keends.atlassian.net/browse/%s)\n",
-				issueNumber,
-				issueNumber,
-			)
-		}
-
-		commitFullBody = commitFullBody + commitTitle + "\n\n" + commitBody + "\n---\n"
-	}
-
-	commitFullBody = commitFullBody + linkBody
-
-	return "", commitFullBody
-}
-
-func getReviewersFilePath() (string, error) {
-	fileName := ".__reviewers.csv"
-	home, err := os.UserHomeDir()
+// getPrePopulatedContent function to derive the pull request title, the body made by the template from the commits
+// and the sections linking the issues that the issue tracker rules find in them
+func getPrePopulatedContent(
+	tmpl *template.Template,
+	data bodyData,
+	rules []config.IssueTrackerRule,
+) (string, string, string, error) {
+	commitBody, err := renderBody(tmpl, data)
 	if err != nil {
-		log.Printf("Failed to get user home directory: %s", err)
+		return "", "", "", fmt.Errorf("failed to render the body template: %w", err)
+	}
+
+	inputs := make([]string, 0, len(data.Commits)*2)
+	for _, commit := range data.Commits {
+		inputs = append(inputs, commit.Summary, commit.Body)
+	}
 
-		return "", err
+	title := ""
+	if len(data.Commits) == 1 {
+		title = data.Commits[0].Summary
 	}
 
-	return filepath.Join(home, fileName), nil
+	return title, commitBody, buildIssueLinkSections(rules, inputs), nil
 }
 
-func writeLatestReviewers(repo string, reviewers []string) error {
-	filePath, err := getReviewersFilePath()
-	if err != nil {
-		return err
+// getPrePopulatedTitleAndBody function to derive the pull request title and the body made by the template from the commits,
+// linking the issues that the issue tracker rules find in them
+func getPrePopulatedTitleAndBody(
+	tmpl *template.Template,
+	data bodyData,
+	rules []config.IssueTrackerRule,
+) (string, string, error) {
+	title, commitBody, linkBody, err := getPrePopulatedContent(tmpl, data, rules)
+	if err != nil || linkBody == "" {
+		return title, commitBody, err
 	}
 
-	// Open the CSV file
-	file, err := os.Create(filePath)
-	if err != nil {
-		log.Fatalf("Failed to open file: %s", err)
+	// A body ending with a line, like the separator of the full messages, is followed by the links right away
+	if strings.HasSuffix(commitBody, "\n") {
+		return title, commitBody + linkBody, nil
 	}
-	defer file.Close()
 
-	// Initialize the CSV reader
-	reader := csv.NewReader(file)
+	return title, commitBody + "\n\n" + linkBody, nil
+}
 
-	// Read all records from the file
-	records, err := reader.ReadAll()
-	if err != nil {
-		log.Fatalf("Failed to read CSV file: %s", err)
+// reviewerCandidate struct to represent a user or a team that can be requested for review
+type reviewerCandidate struct {
+	// value is the login of a user or the "org/team" slug of a team
+	value string
+	name  string
+}
+
+// userCandidates function to get the users that can be requested for review, leaving out the current user
+func userCandidates(users []gh_command.RepoAssignableUser, myUserLogin string) []reviewerCandidate {
+	candidates := make([]reviewerCandidate, 0, len(users))
+	for _, user := range users {
+		if user.Login != myUserLogin {
+			candidates = append(candidates, reviewerCandidate{value: user.Login, name: user.Name})
+		}
+	}
+
+	return candidates
+}
+
+// teamCandidates function to get the teams of the organization that can be requested for review
+func teamCandidates(teams []gh_command.RepoTeam, organization string) []reviewerCandidate {
+	candidates := make([]reviewerCandidate, 0, len(teams))
+	for _, team := range teams {
+		candidates = append(candidates, reviewerCandidate{value: organization + "/" + team.Slug, name: team.Name})
 	}
 
-	// Track if repo was found
-	repoFound := false
+	return candidates
+}
 
-	// first column is repo name, second column is reviewers
-	// if repo name already exists, update the reviewers
-	// if repo name does not exist, append the repo name and reviewers
-	for i, record := range records {
-		if record[0] == repo {
-			records[i][1] = strings.Join(reviewers, ",")
-			repoFound = true
-			break
+// splitTeamReviewers function to split reviewers into user logins and "org/team" slugs
+func splitTeamReviewers(reviewers []string) ([]string, []string) {
+	users := make([]string, 0, len(reviewers))
+	teams := make([]string, 0)
+	for _, reviewer := range reviewers {
+		if strings.Contains(reviewer, "/") {
+			teams = append(teams, reviewer)
+		} else {
+			users = append(users, reviewer)
 		}
 	}
 
-	// If repo was not found, append a new record
-	if !repoFound {
-		newRecord := []string{repo, strings.Join(reviewers, ",")}
-		records = append(records, newRecord)
+	return users, teams
+}
+
+// requestableReviewers function to keep the values that are among the candidates, in their order
+func requestableReviewers(candidates []reviewerCandidate, values []string) []string {
+	requestable := make(map[string]bool)
+	for _, candidate := range candidates {
+		requestable[candidate.value] = true
 	}
 
-	// Move the file pointer back to the beginning to overwrite the file
-	if _, err := file.Seek(0, 0); err != nil {
-		log.Fatalf("Failed to seek file: %s", err)
-		return err
+	kept := make([]string, 0, len(values))
+	for _, value := range values {
+		if requestable[value] {
+			kept = append(kept, value)
+		}
 	}
 
-	// write the updated records to the file
-	writer := csv.NewWriter(file)
-	err = writer.WriteAll(records)
-	if err != nil {
-		log.Fatalf("Failed to write CSV file: %s", err)
-		return err
+	return kept
+}
+
+// suggestedReviewers function to get the top ranked reviewers that are among the candidates
+func suggestedReviewers(candidates []reviewerCandidate, ranks []state.ReviewerRank, count int) []string {
+	logins := make([]string, 0, len(ranks))
+	for _, rank := range ranks {
+		logins = append(logins, rank.Login)
 	}
 
-	writer.Flush()
+	suggested := requestableReviewers(candidates, logins)
+	if len(suggested) > count {
+		suggested = suggested[:count]
+	}
 
-	return writer.Error()
+	return suggested
 }
 
-func getLatestReviewers(repo string) ([]string, error) {
-	filePath, err := getReviewersFilePath()
-	if err != nil {
-		return nil, err
+// reviewerOptions function to build the options of a reviewer picker.
+// The reviewers chosen before come first in the order of their rank, the suggested ones marked with a star,
+// then the code owners of the changed files, and everybody else follows in alphabetical order.
+func reviewerOptions(
+	candidates []reviewerCandidate,
+	ranks []state.ReviewerRank,
+	suggested []string,
+	codeOwners []string,
+) []huh.Option[string] {
+	nameByValue := make(map[string]string)
+	for _, candidate := range candidates {
+		nameByValue[candidate.value] = candidate.name
 	}
 
-	// Open the CSV file
-	file, err := os.Open(filePath)
-	if err != nil {
-		log.Printf("Failed to open file: %s", err)
-		return nil, err
+	isSuggested := make(map[string]bool)
+	for _, value := range suggested {
+		isSuggested[value] = true
 	}
-	defer file.Close()
 
-	// Initialize the CSV reader
-	reader := csv.NewReader(file)
+	isCodeOwner := make(map[string]bool)
+	for _, value := range codeOwners {
+		isCodeOwner[value] = true
+	}
 
-	// Read all records from the file
-	records, err := reader.ReadAll()
-	if err != nil {
-		log.Printf("Failed to read CSV file: %s", err)
-		return nil, err
+	label := func(value string) string {
+		text := value
+		if name := nameByValue[value]; name != "" {
+			text = fmt.Sprintf("%s (%s)", value, name)
+		}
+		if isCodeOwner[value] {
+			text += " · code owner"
+		}
+		if isSuggested[value] {
+			text = "★ " + text
+		}
+		return text
 	}
 
-	reviewers := []string{}
-	// first column is repo name, second column is reviewers
+	options := make([]huh.Option[string], 0, len(nameByValue))
+	listed := make(map[string]bool)
+	for _, value := range suggestedReviewers(candidates, ranks, len(ranks)) {
+		listed[value] = true
+		options = append(options, huh.NewOption(label(value), value))
+	}
+
+	for _, value := range requestableReviewers(candidates, codeOwners) {
+		if !listed[value] {
+			listed[value] = true
+			options = append(options, huh.NewOption(label(value), value))
+		}
+	}
+
+	unlisted := make([]string, 0, len(nameByValue))
+	for value := range nameByValue {
+		if !listed[value] {
+			unlisted = append(unlisted, value)
+		}
+	}
+	sort.Strings(unlisted)
+	for _, value := range unlisted {
+		options = append(options, huh.NewOption(label(value), value))
+	}
 
-	for _, record := range records {
-		if record[0] == repo {
-			reviewers = strings.Split(record[1], ",")
-			break
+	return options
+}
+
+// keepKnown function to keep the values that are among the known ones, in their order
+func keepKnown(values []string, known []string) []string {
+	kept := make([]string, 0, len(values))
+	for _, value := range values {
+		if slices.Contains(known, value) {
+			kept = append(kept, value)
+		}
+	}
+
+	return kept
+}
+
+// assigneeOptions function to build the options of the assignee picker, the current user first
+func assigneeOptions(users []gh_command.RepoAssignableUser, myUserLogin string) []huh.Option[string] {
+	options := make([]huh.Option[string], 0, len(users))
+	for _, user := range users {
+		text := user.Login
+		if user.Name != "" {
+			text = fmt.Sprintf("%s (%s)", user.Login, user.Name)
+		}
+		if user.Login == myUserLogin {
+			options = append([]huh.Option[string]{huh.NewOption(text+" · you", user.Login)}, options...)
+		} else {
+			options = append(options, huh.NewOption(text, user.Login))
 		}
 	}
 
-	return reviewers, nil
+	return options
 }
@@ -4,46 +4,88 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/charmbracelet/huh"
+	"github.com/coding-for-fun-org/lazygithub/pkg/config"
 	"github.com/coding-for-fun-org/lazygithub/pkg/gh_command"
 	"github.com/coding-for-fun-org/lazygithub/pkg/git_command"
+	"github.com/coding-for-fun-org/lazygithub/pkg/state"
 )
 
-func Test_extractPatterns(t *testing.T) {
+var jiraRule = config.IssueTrackerRule{
+	Pattern: `[A-Z]+-\d+`,
+	URL:     "https://keends.atlassian.net/browse/{key}",
+	Heading: "Jira Link",
+}
+
+func Test_extractIssueLinks(t *testing.T) {
 	type args struct {
+		rule  config.IssueTrackerRule
 		input string
 	}
 	tests := []struct {
 		name    string
 		args    args
-		want    []string
+		want    []issueLink
 		wantErr bool
 	}{
 		{
 			name: "base test case",
 			args: args{
+				rule:  jiraRule,
 				input: "ABC-123",
 			},
-			want:    []string{"ABC-123"},
+			want: []issueLink{
+				{key: "ABC-123", url: "https://keends.atlassian.net/browse/ABC-123"},
+			},
 			wantErr: false,
 		},
 		{
 			name: "multiple patterns",
 			args: args{
+				rule:  jiraRule,
 				input: "fix(ABC-456,DEF-146): this is test commit.\n\nThis is related to XYZ-7890",
 			},
-			want:    []string{"ABC-456", "DEF-146", "XYZ-7890"},
+			want: []issueLink{
+				{key: "ABC-456", url: "https://keends.atlassian.net/browse/ABC-456"},
+				{key: "DEF-146", url: "https://keends.atlassian.net/browse/DEF-146"},
+				{key: "XYZ-7890", url: "https://keends.atlassian.net/browse/XYZ-7890"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "key group and regular expression groups in the url",
+			args: args{
+				rule: config.IssueTrackerRule{
+					Pattern: `(?:^|\s)#(?P<key>\d+)`,
+					URL:     "https://github.com/owner/repo/issues/${key}",
+				},
+				input: "fix: close #12 and #345",
+			},
+			want: []issueLink{
+				{key: "12", url: "https://github.com/owner/repo/issues/12"},
+				{key: "345", url: "https://github.com/owner/repo/issues/345"},
+			},
 			wantErr: false,
 		},
+		{
+			name: "invalid pattern",
+			args: args{
+				rule:  config.IssueTrackerRule{Pattern: "[A-Z"},
+				input: "ABC-123",
+			},
+			want:    nil,
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := extractPatterns(tt.args.input)
+			got, err := extractIssueLinks(tt.args.rule, tt.args.input)
 			if (err != nil) != tt.wantErr {
-				t.Errorf("extractPatterns() error = %v, wantErr %v", err, tt.wantErr)
+				t.Errorf("extractIssueLinks() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
 			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("extractPatterns() = %v, want %v", got, tt.want)
+				t.Errorf("extractIssueLinks() = %v, want %v", got, tt.want)
 			}
 		})
 	}
@@ -135,6 +177,7 @@ func TestCreatePullRequest_getPrePopulatedTitleAndBody(t *testing.T) {
 	}
 	type args struct {
 		commits []gh_command.Commit
+		rules   []config.IssueTrackerRule
 	}
 	tests := []struct {
 		name   string
@@ -155,14 +198,67 @@ func TestCreatePullRequest_getPrePopulatedTitleAndBody(t *testing.T) {
 						Message: "feat(ABC-123): this is test commit.\n\nThis is related to XYZ-7890",
 					},
 				},
+				rules: []config.IssueTrackerRule{jiraRule},
 			},
 			want:  "feat(ABC-123): this is test commit.",
 			want1: "This is related to XYZ-7890\n\n### Jira Link\n\n[ABC-123](https://keends.atlassian.net/browse/ABC-123)\n[XYZ-7890](https://keends.atlassian.net/browse/XYZ-7890)\n",
 		},
+		{
+			name: "no issue tracker rules",
+			args: args{
+				commits: []gh_command.Commit{
+					{
+						Message: "feat(ABC-123): this is test commit.\n\nThis is related to XYZ-7890",
+					},
+				},
+			},
+			want:  "feat(ABC-123): this is test commit.",
+			want1: "This is related to XYZ-7890",
+		},
+		{
+			name: "multiple commits and multiple trackers",
+			args: args{
+				commits: []gh_command.Commit{
+					{
+						Message: "feat(ABC-123): first commit\n\nCloses #7",
+					},
+					{
+						Message: "fix: second commit for ABC-123\n\nSee ENG-42",
+					},
+				},
+				rules: []config.IssueTrackerRule{
+					jiraRule,
+					{
+						Pattern: `ENG-\d+`,
+						URL:     "https://linear.app/issue/{key}",
+						Heading: "Linear Link",
+					},
+					{
+						Pattern: `(?:^|\s)#(?P<key>\d+)`,
+						URL:     "https://github.com/owner/repo/issues/{key}",
+						Heading: "Related Issues",
+					},
+				},
+			},
+			want: "",
+			want1: "feat(ABC-123): first commit\n\nCloses #7\n---\n" +
+				"fix: second commit for ABC-123\n\nSee ENG-42\n---\n" +
+				"### Jira Link\n\n[ABC-123](https://keends.atlassian.net/browse/ABC-123)\n[ENG-42](https://keends.atlassian.net/browse/ENG-42)\n" +
+				"\n### Linear Link\n\n[ENG-42](https://linear.app/issue/ENG-42)\n" +
+				"\n### Related Issues\n\n[7](https://github.com/owner/repo/issues/7)\n",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, got1 := getPrePopulatedTitleAndBody(tt.args.commits)
+			tmpl, err := parseBodyTemplate(config.BodyPresetFull, bodyPresets[config.BodyPresetFull])
+			if err != nil {
+				t.Fatal(err)
+			}
+			data := newBodyData(tt.args.commits, tt.args.rules, "feature", "main", nil)
+			got, got1, err := getPrePopulatedTitleAndBody(tmpl, data, tt.args.rules)
+			if err != nil {
+				t.Fatalf("CreatePullRequest.getPrePopulatedTitleAndBody() error = %v", err)
+			}
 			if got != tt.want {
 				t.Errorf(
 					"CreatePullRequest.getPrePopulatedTitleAndBody() got = %v, want %v",
@@ -180,3 +276,109 @@ func TestCreatePullRequest_getPrePopulatedTitleAndBody(t *testing.T) {
 		})
 	}
 }
+
+func Test_reviewerOptions(t *testing.T) {
+	users := []gh_command.RepoAssignableUser{
+		{Login: "alice", Name: "Alice"},
+		{Login: "bob"},
+		{Login: "carol", Name: "Carol"},
+		{Login: "dave"},
+		{Login: "me"},
+	}
+	teams := []gh_command.RepoTeam{
+		{ID: "T_1", Slug: "backend", Name: "Backend"},
+		{ID: "T_2", Slug: "docs"},
+	}
+	tests := []struct {
+		name       string
+		candidates []reviewerCandidate
+		ranks      []state.ReviewerRank
+		suggested  []string
+		codeOwners []string
+		want       []huh.Option[string]
+	}{
+		{
+			name:       "users ranked with the suggested ones starred and without the author",
+			candidates: userCandidates(users, "me"),
+			ranks: []state.ReviewerRank{
+				{Login: "me", Score: 5},
+				{Login: "carol", Score: 3},
+				{Login: "gone", Score: 2},
+				{Login: "bob", Score: 1},
+			},
+			suggested:  []string{"carol"},
+			codeOwners: []string{"dave", "carol", "me"},
+			want: []huh.Option[string]{
+				huh.NewOption("★ carol (Carol) · code owner", "carol"),
+				huh.NewOption("bob", "bob"),
+				huh.NewOption("dave · code owner", "dave"),
+				huh.NewOption("alice (Alice)", "alice"),
+			},
+		},
+		{
+			name:       "teams of the organization ranked among the users",
+			candidates: teamCandidates(teams, "octo-org"),
+			ranks: []state.ReviewerRank{
+				{Login: "alice", Score: 3},
+				{Login: "octo-org/docs", Score: 2},
+			},
+			codeOwners: []string{"octo-org/backend", "other-org/qa"},
+			want: []huh.Option[string]{
+				huh.NewOption("octo-org/docs", "octo-org/docs"),
+				huh.NewOption("octo-org/backend (Backend) · code owner", "octo-org/backend"),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := reviewerOptions(tt.candidates, tt.ranks, tt.suggested, tt.codeOwners)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("reviewerOptions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_suggestedReviewers(t *testing.T) {
+	candidates := userCandidates([]gh_command.RepoAssignableUser{{Login: "bob"}, {Login: "carol"}, {Login: "me"}}, "me")
+	ranks := []state.ReviewerRank{
+		{Login: "me", Score: 5},
+		{Login: "carol", Score: 3},
+		{Login: "gone", Score: 2},
+		{Login: "bob", Score: 1},
+	}
+
+	if got := suggestedReviewers(candidates, ranks, 2); !reflect.DeepEqual(got, []string{"carol", "bob"}) {
+		t.Errorf("suggestedReviewers() = %v, want [carol bob]", got)
+	}
+}
+
+func Test_splitTeamReviewers(t *testing.T) {
+	tests := []struct {
+		name      string
+		reviewers []string
+		wantUsers []string
+		wantTeams []string
+	}{
+		{
+			name:      "users and teams",
+			reviewers: []string{"alice", "octo-org/docs", "bob"},
+			wantUsers: []string{"alice", "bob"},
+			wantTeams: []string{"octo-org/docs"},
+		},
+		{
+			name:      "users only",
+			reviewers: []string{"alice"},
+			wantUsers: []string{"alice"},
+			wantTeams: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users, teams := splitTeamReviewers(tt.reviewers)
+			if !reflect.DeepEqual(users, tt.wantUsers) || !reflect.DeepEqual(teams, tt.wantTeams) {
+				t.Errorf("splitTeamReviewers() = %v, %v, want %v, %v", users, teams, tt.wantUsers, tt.wantTeams)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package cli_prompt
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// editorCommentPrefix starts the lines that are removed from the edited text.
+// Markdown headings start with "#", so the comments use ";" like git does with core.commentChar set to it.
+const editorCommentPrefix = ";"
+
+// defaultEditor is the editor used when neither $VISUAL nor $EDITOR is set, the same as git
+const defaultEditor = "vi"
+
+// editorCommand function to get the editor command and its arguments from $VISUAL or $EDITOR
+func editorCommand() []string {
+	for _, name := range []string{"VISUAL", "EDITOR"} {
+		if command := strings.Fields(os.Getenv(name)); len(command) > 0 {
+			return command
+		}
+	}
+
+	return []string{defaultEditor}
+}
+
+// stripComments function to remove the comment lines of an edited text and the blank lines left at its end,
+// the way git cleans up a commit message
+func stripComments(text string) string {
+	lines := make([]string, 0)
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		if !strings.HasPrefix(line, editorCommentPrefix) {
+			lines = append(lines, line)
+		}
+	}
+
+	return strings.TrimRight(strings.Join(lines, "\n"), " \t\n")
+}
+
+// editInEditor function to let the user edit a text in their editor, through a temporary file with the given extension.
+// The help lines are appended as comments and removed again with the other comment lines.
+func editInEditor(ctx context.Context, text string, extension string, help []string) (string, error) {
+	file, err := os.CreateTemp("", "lazygithub-*"+extension)
+	if err != nil {
+		return "", fmt.Errorf("failed to create the file to edit: %w", err)
+	}
+	defer os.Remove(file.Name())
+
+	content := strings.TrimRight(text, "\n") + "\n\n"
+	for _, line := range help {
+		content += editorCommentPrefix + " " + line + "\n"
+	}
+	_, err = file.WriteString(content)
+	if errClose := file.Close(); err == nil {
+		err = errClose
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to write the file to edit: %w", err)
+	}
+
+	command := editorCommand()
+	cmd := exec.CommandContext(ctx, command[0], append(command[1:], file.Name())...)
+	// The editor takes over the terminal until it exits
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("the editor %s failed: %w", command[0], err)
+	}
+
+	edited, err := os.ReadFile(file.Name())
+	if err != nil {
+		return "", fmt.Errorf("failed to read the edited file: %w", err)
+	}
+
+	return stripComments(string(edited)), nil
+}
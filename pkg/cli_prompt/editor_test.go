@@ -0,0 +1,51 @@
+package cli_prompt
+
+import (
+	"context"
+	"testing"
+)
+
+func Test_stripComments(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{
+			name: "comment lines and trailing blank lines",
+			text: "Body\n\n; help\n; more help\n\n",
+			want: "Body",
+		},
+		{
+			name: "markdown headings and html comments are kept",
+			text: "## Summary\n<!-- from the template -->\n; help\nDone\r\n",
+			want: "## Summary\n<!-- from the template -->\nDone",
+		},
+		{
+			name: "only comments",
+			text: "; help\n",
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := stripComments(tt.text); got != tt.want {
+				t.Errorf("stripComments() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_editInEditor(t *testing.T) {
+	// $VISUAL wins over $EDITOR, and the editor gets the file as its last argument
+	t.Setenv("VISUAL", "sed -i s/draft/final/")
+	t.Setenv("EDITOR", "false")
+
+	got, err := editInEditor(context.Background(), "The draft body\n", ".md", []string{"the draft help"})
+	if err != nil {
+		t.Fatalf("editInEditor() error = %v", err)
+	}
+	if want := "The final body"; got != want {
+		t.Errorf("editInEditor() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,118 @@
+package cli_prompt
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	// commitsPlaceholder is replaced with the content derived from the commits
+	commitsPlaceholder = "<!-- lazygithub:commits -->"
+	// issueLinksPlaceholder is replaced with the issue link sections
+	issueLinksPlaceholder = "<!-- lazygithub:issue-links -->"
+)
+
+// pullRequestTemplate struct to represent a pull request template found in the repository
+type pullRequestTemplate struct {
+	// path is relative to the repository root
+	path    string
+	content string
+}
+
+// pullRequestTemplateDirs are the directories GitHub looks for pull request templates in
+var pullRequestTemplateDirs = []string{".github", "", "docs"}
+
+// findEntry function to find a directory entry by name, ignoring the case like GitHub does
+func findEntry(entries []os.DirEntry, name string, isDir bool) (os.DirEntry, bool) {
+	for _, entry := range entries {
+		if entry.IsDir() == isDir && strings.EqualFold(entry.Name(), name) {
+			return entry, true
+		}
+	}
+
+	return nil, false
+}
+
+// findPullRequestTemplates function to find the pull request templates of the repository.
+// The single file templates come first, in the order GitHub prefers them.
+func findPullRequestTemplates(root string) ([]pullRequestTemplate, error) {
+	singles := make([]string, 0)
+	multiples := make([]string, 0)
+
+	for _, dir := range pullRequestTemplateDirs {
+		entries, err := os.ReadDir(filepath.Join(root, dir))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if entry, ok := findEntry(entries, "pull_request_template.md", false); ok {
+			singles = append(singles, filepath.Join(dir, entry.Name()))
+		}
+
+		if entry, ok := findEntry(entries, "PULL_REQUEST_TEMPLATE", true); ok {
+			templateDir := filepath.Join(dir, entry.Name())
+			templateEntries, err := os.ReadDir(filepath.Join(root, templateDir))
+			if err != nil {
+				return nil, err
+			}
+			names := make([]string, 0)
+			for _, templateEntry := range templateEntries {
+				if !templateEntry.IsDir() && strings.EqualFold(filepath.Ext(templateEntry.Name()), ".md") {
+					names = append(names, filepath.Join(templateDir, templateEntry.Name()))
+				}
+			}
+			sort.Strings(names)
+			multiples = append(multiples, names...)
+		}
+	}
+
+	templates := make([]pullRequestTemplate, 0)
+	for _, path := range append(singles, multiples...) {
+		content, err := os.ReadFile(filepath.Join(root, path))
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, pullRequestTemplate{path: path, content: string(content)})
+	}
+
+	return templates, nil
+}
+
+// applyPullRequestTemplate function to merge the commit content and the issue links into the template.
+// Content without a placeholder in the template is appended under the heading.
+func applyPullRequestTemplate(template string, commitBody string, linkBody string, heading string) string {
+	body := template
+	rest := make([]string, 0)
+
+	if strings.Contains(body, commitsPlaceholder) {
+		body = strings.ReplaceAll(body, commitsPlaceholder, strings.TrimSpace(commitBody))
+	} else if strings.TrimSpace(commitBody) != "" {
+		rest = append(rest, strings.TrimSpace(commitBody))
+	}
+
+	if strings.Contains(body, issueLinksPlaceholder) {
+		body = strings.ReplaceAll(body, issueLinksPlaceholder, strings.TrimSpace(linkBody))
+	} else if strings.TrimSpace(linkBody) != "" {
+		rest = append(rest, strings.TrimSpace(linkBody))
+	}
+
+	if len(rest) == 0 {
+		return body
+	}
+
+	body = strings.TrimRight(body, "\n")
+	if body != "" {
+		body += "\n\n"
+	}
+	if heading != "" {
+		body += "## " + heading + "\n\n"
+	}
+
+	return body + strings.Join(rest, "\n\n") + "\n"
+}
@@ -0,0 +1,126 @@
+package cli_prompt
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func Test_findPullRequestTemplates(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  []pullRequestTemplate
+	}{
+		{
+			name:  "no templates",
+			files: map[string]string{"README.md": "readme"},
+			want:  []pullRequestTemplate{},
+		},
+		{
+			name: "single templates come before the template directories",
+			files: map[string]string{
+				".github/PULL_REQUEST_TEMPLATE/b.md":   "b",
+				".github/PULL_REQUEST_TEMPLATE/a.md":   "a",
+				".github/PULL_REQUEST_TEMPLATE/a.txt":  "ignored",
+				"docs/pull_request_template.md":        "docs",
+				"PULL_REQUEST_TEMPLATE/root.md":        "root",
+				".github/Pull_Request_Template.md":     "github",
+				"docs/other/pull_request_template.md":  "ignored",
+				"pull_request_template_not_really.md":  "ignored",
+				".github/ISSUE_TEMPLATE/bug_report.md": "ignored",
+			},
+			want: []pullRequestTemplate{
+				{path: ".github/Pull_Request_Template.md", content: "github"},
+				{path: "docs/pull_request_template.md", content: "docs"},
+				{path: ".github/PULL_REQUEST_TEMPLATE/a.md", content: "a"},
+				{path: ".github/PULL_REQUEST_TEMPLATE/b.md", content: "b"},
+				{path: "PULL_REQUEST_TEMPLATE/root.md", content: "root"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			for path, content := range tt.files {
+				fullPath := filepath.Join(root, path)
+				if err := os.MkdirAll(filepath.Dir(fullPath), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(fullPath, []byte(content), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got, err := findPullRequestTemplates(root)
+			if err != nil {
+				t.Errorf("findPullRequestTemplates() error = %v", err)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("findPullRequestTemplates() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_applyPullRequestTemplate(t *testing.T) {
+	type args struct {
+		template   string
+		commitBody string
+		linkBody   string
+		heading    string
+	}
+	tests := []struct {
+		name string
+		args args
+		want string
+	}{
+		{
+			name: "append under the heading",
+			args: args{
+				template:   "## Checklist\n\n- [ ] Tests\n",
+				commitBody: "This is related to XYZ-7890",
+				linkBody:   "### Jira Link\n\n[XYZ-7890](https://example.com/XYZ-7890)\n",
+				heading:    "Changes",
+			},
+			want: "## Checklist\n\n- [ ] Tests\n\n## Changes\n\nThis is related to XYZ-7890\n\n### Jira Link\n\n[XYZ-7890](https://example.com/XYZ-7890)\n",
+		},
+		{
+			name: "replace the placeholders",
+			args: args{
+				template:   "## Summary\n\n<!-- lazygithub:commits -->\n\n## Issues\n\n<!-- lazygithub:issue-links -->\n",
+				commitBody: "This is related to XYZ-7890\n",
+				linkBody:   "### Jira Link\n\n[XYZ-7890](https://example.com/XYZ-7890)\n",
+				heading:    "Changes",
+			},
+			want: "## Summary\n\nThis is related to XYZ-7890\n\n## Issues\n\n### Jira Link\n\n[XYZ-7890](https://example.com/XYZ-7890)\n",
+		},
+		{
+			name: "append only the content without a placeholder",
+			args: args{
+				template:   "## Summary\n\n<!-- lazygithub:commits -->\n",
+				commitBody: "Body",
+				linkBody:   "### Jira Link\n\n[XYZ-7890](https://example.com/XYZ-7890)\n",
+				heading:    "",
+			},
+			want: "## Summary\n\nBody\n\n### Jira Link\n\n[XYZ-7890](https://example.com/XYZ-7890)\n",
+		},
+		{
+			name: "nothing to merge",
+			args: args{
+				template: "## Checklist\n",
+				heading:  "Changes",
+			},
+			want: "## Checklist\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := applyPullRequestTemplate(tt.args.template, tt.args.commitBody, tt.args.linkBody, tt.args.heading); got != tt.want {
+				t.Errorf("applyPullRequestTemplate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
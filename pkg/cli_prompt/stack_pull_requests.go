@@ -0,0 +1,299 @@
+package cli_prompt
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/huh"
+	"github.com/coding-for-fun-org/lazygithub/pkg/gh_command"
+	"github.com/coding-for-fun-org/lazygithub/pkg/git_command"
+)
+
+// Markers around the generated stack section of a pull request body, everything between them is replaced on every run
+const (
+	stackSectionStart = "<!-- lazygithub:stack -->"
+	stackSectionEnd   = "<!-- /lazygithub:stack -->"
+)
+
+// stackSectionNumberPattern matches the number of a pull request listed in a stack section
+var stackSectionNumberPattern = regexp.MustCompile(`(?m)^- (?:\*\*)?#(\d+) `)
+
+// StackPullRequestsOptions struct to represent the values given from the command line for a stack of pull requests
+type StackPullRequestsOptions struct {
+	// Repo is the repository in the OWNER/REPO format, empty to resolve it from the git remotes
+	Repo string
+	// Hostname is the GitHub host, empty to use the default one
+	Hostname string
+	// Head is the top branch of the stack, empty for the checked out branch
+	Head string
+	// Base is the branch the bottom of the stack is merged into, empty for the default branch
+	Base string
+	// Draft is nil when the draft state is asked for each pull request
+	Draft *bool
+	// Push is nil when pushing is asked for each branch
+	Push *bool
+	Yes  bool
+}
+
+// StackPullRequests struct to create or update one pull request per branch of a stack,
+// each one based on the branch below it
+type StackPullRequests struct {
+	options  StackPullRequestsOptions
+	repoHost string
+	// repo is the "host/owner/name" of the resolved repository, so that every pull request goes to the same one
+	repo string
+	// stack is the chain of branches from the bottom to the head
+	stack        []string
+	baseBranch   string
+	pullRequests []gh_command.PullRequest
+}
+
+// NewStackPullRequests function to create a stack prompt with the given options
+func NewStackPullRequests(options StackPullRequestsOptions) *StackPullRequests {
+	return &StackPullRequests{options: options}
+}
+
+// detectStack method to find the branches the head branch is stacked on among the local branches
+func (s *StackPullRequests) detectStack(ctx context.Context) error {
+	r := gh_command.Repo{Hostname: s.options.Hostname, RepoName: s.options.Repo}
+	repo, err := r.Get(ctx, gh_command.GetRepoOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to load the repository: %w", err)
+	}
+	s.repoHost = repo.Host
+	s.repo = repo.Host + "/" + repo.Owner.Login + "/" + repo.Name
+
+	s.baseBranch = s.options.Base
+	if s.baseBranch == "" {
+		s.baseBranch = repo.DefaultBranchRef.Name
+	}
+
+	head := s.options.Head
+	if head == "" {
+		head, err = git_command.GetCurrentBranch(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get the checked out branch: %w", err)
+		}
+		if head == "" {
+			return fmt.Errorf("HEAD is detached, give the top branch of the stack with --head")
+		}
+	}
+	if head == s.baseBranch {
+		return fmt.Errorf("%s is the base branch, check out the top branch of the stack or give it with --head", head)
+	}
+
+	latestBranches, err := git_command.ListLatestBranches(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list the local branches: %w", err)
+	}
+	branches := make([]string, 0, len(latestBranches))
+	for _, branch := range latestBranches {
+		branches = append(branches, branch.Ref)
+	}
+
+	// Like the commits of a pull request, the stack is compared with the base the remote has
+	baseRef := s.baseBranch
+	status, ok, err := git_command.GetBranchStatus(ctx, s.baseBranch)
+	if err != nil {
+		return fmt.Errorf("failed to check the upstream of %s: %w", s.baseBranch, err)
+	}
+	if ok && status.Upstream != "" && !status.Gone {
+		baseRef = status.Upstream
+	}
+
+	stack, err := git_command.FindStack(ctx, head, baseRef, branches)
+	if err != nil {
+		return fmt.Errorf("failed to find the branches %s is stacked on: %w", head, err)
+	}
+	s.stack = stack
+
+	return nil
+}
+
+// baseOf method to get the base branch of the pull request of the i-th branch of the stack
+func (s *StackPullRequests) baseOf(i int) string {
+	if i == 0 {
+		return s.baseBranch
+	}
+
+	return s.stack[i-1]
+}
+
+// stackSection function to build the stack section of the body of the i-th pull request.
+// The stack is listed from the top down to the base branch, the way it is merged from the bottom up.
+func stackSection(pullRequests []gh_command.PullRequest, baseBranch string, i int) string {
+	lines := []string{stackSectionStart, "### Stack", ""}
+	for j := len(pullRequests) - 1; j >= 0; j-- {
+		line := fmt.Sprintf("- #%d `%s`", pullRequests[j].Number, pullRequests[j].HeadRefName)
+		if j == i {
+			line = fmt.Sprintf("- **#%d `%s`** ← this pull request", pullRequests[j].Number, pullRequests[j].HeadRefName)
+		}
+		lines = append(lines, line)
+	}
+	lines = append(lines, fmt.Sprintf("- `%s`", baseBranch), stackSectionEnd)
+
+	return strings.Join(lines, "\n")
+}
+
+// withStackSection function to put the stack section into a body,
+// replacing the one written by an earlier run or appending it when there is none
+func withStackSection(body string, section string) string {
+	start := strings.Index(body, stackSectionStart)
+	end := strings.Index(body, stackSectionEnd)
+	if start >= 0 && end > start {
+		return body[:start] + section + body[end+len(stackSectionEnd):]
+	}
+
+	body = strings.TrimRight(body, " \t\r\n")
+	if body == "" {
+		return section
+	}
+
+	return body + "\n\n" + section
+}
+
+// stackSectionNumbers function to get the numbers of the pull requests listed in the stack section of a body
+func stackSectionNumbers(body string) []int {
+	start := strings.Index(body, stackSectionStart)
+	end := strings.Index(body, stackSectionEnd)
+	if start < 0 || end < start {
+		return nil
+	}
+
+	numbers := make([]int, 0)
+	for _, match := range stackSectionNumberPattern.FindAllStringSubmatch(body[start:end], -1) {
+		if number, err := strconv.Atoi(match[1]); err == nil {
+			numbers = append(numbers, number)
+		}
+	}
+
+	return numbers
+}
+
+// withoutStackSection function to remove the stack section from a body, along with the blank lines before it
+func withoutStackSection(body string) string {
+	start := strings.Index(body, stackSectionStart)
+	end := strings.Index(body, stackSectionEnd)
+	if start < 0 || end < start {
+		return body
+	}
+
+	before := strings.TrimRight(body[:start], " \t\r\n")
+	after := body[end+len(stackSectionEnd):]
+	if before == "" {
+		return strings.TrimLeft(after, " \t\r\n")
+	}
+
+	return before + after
+}
+
+// updateStackSections method to rewrite the stack section of every pull request that does not list the stack as it is now,
+// and to remove it from the pull requests that the earlier sections listed but that left the stack, e.g. after a rebase
+func (s *StackPullRequests) updateStackSections(ctx context.Context) error {
+	current := make([]int, 0, len(s.pullRequests))
+	for _, pullRequest := range s.pullRequests {
+		current = append(current, pullRequest.Number)
+	}
+	stale := make([]int, 0)
+	for _, pullRequest := range s.pullRequests {
+		for _, number := range stackSectionNumbers(pullRequest.Body) {
+			if !slices.Contains(current, number) && !slices.Contains(stale, number) {
+				stale = append(stale, number)
+			}
+		}
+	}
+
+	for i, pullRequest := range s.pullRequests {
+		body := withStackSection(pullRequest.Body, stackSection(s.pullRequests, s.baseBranch, i))
+		if body == pullRequest.Body {
+			continue
+		}
+
+		err := gh_command.UpdatePullRequest(ctx, gh_command.UpdatePullRequestOptions{
+			Hostname:      s.repoHost,
+			PullRequestID: pullRequest.ID,
+			Body:          &body,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to update the stack section of pull request #%d: %w", pullRequest.Number, err)
+		}
+		s.pullRequests[i].Body = body
+	}
+
+	for _, number := range stale {
+		pullRequest, err := gh_command.GetPullRequest(ctx, s.repoHost, s.repo, strconv.Itoa(number))
+		if err != nil {
+			return fmt.Errorf("failed to load pull request #%d that left the stack: %w", number, err)
+		}
+		body := withoutStackSection(pullRequest.Body)
+		if body == pullRequest.Body {
+			continue
+		}
+
+		err = gh_command.UpdatePullRequest(ctx, gh_command.UpdatePullRequestOptions{
+			Hostname:      s.repoHost,
+			PullRequestID: pullRequest.ID,
+			Body:          &body,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to remove the stack section of pull request #%d: %w", number, err)
+		}
+	}
+
+	return nil
+}
+
+// Run method to detect the stack of the head branch, create or update the pull request of each of its branches
+// from the bottom up, and then link them to each other in their bodies
+func (s *StackPullRequests) Run(ctx context.Context) error {
+	interactive := isInteractive()
+	if !interactive && !s.options.Yes {
+		return missingOptionsError([]string{"yes"})
+	}
+
+	if err := runWithSpinner(ctx, "Detecting the stack", s.detectStack); err != nil {
+		return err
+	}
+
+	fmt.Printf("Stack: %s ← %s\n", s.baseBranch, strings.Join(s.stack, " ← "))
+
+	if !s.options.Yes {
+		confirmed := true
+		confirmForm := huh.NewForm(
+			huh.NewGroup(
+				huh.NewConfirm().
+					Title(fmt.Sprintf("Create or update the pull requests of these %d branches?", len(s.stack))).
+					Value(&confirmed),
+			),
+		)
+		if err := confirmForm.Run(); err != nil {
+			return err
+		}
+		if confirmForm.State == huh.StateAborted || !confirmed {
+			return ErrAborted
+		}
+	}
+
+	s.pullRequests = make([]gh_command.PullRequest, 0, len(s.stack))
+	for i, branch := range s.stack {
+		p := NewCreatePullRequest(CreatePullRequestOptions{
+			Repo:     s.repo,
+			Hostname: s.options.Hostname,
+			Head:     branch,
+			Base:     s.baseOf(i),
+			Draft:    s.options.Draft,
+			Push:     s.options.Push,
+			Yes:      s.options.Yes,
+		})
+		if err := p.Run(ctx); err != nil {
+			return fmt.Errorf("failed to create or update the pull request of %s: %w", branch, err)
+		}
+		s.pullRequests = append(s.pullRequests, p.pullRequest)
+	}
+
+	return runWithSpinner(ctx, "Linking the pull requests of the stack", s.updateStackSections)
+}
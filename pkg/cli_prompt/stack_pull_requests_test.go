@@ -0,0 +1,138 @@
+package cli_prompt
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/coding-for-fun-org/lazygithub/pkg/command_runner"
+	"github.com/coding-for-fun-org/lazygithub/pkg/gh_command"
+)
+
+func Test_stackSection(t *testing.T) {
+	pullRequests := []gh_command.PullRequest{
+		{Number: 1, HeadRefName: "feature-1"},
+		{Number: 2, HeadRefName: "feature-2"},
+		{Number: 3, HeadRefName: "feature-3"},
+	}
+
+	want := "<!-- lazygithub:stack -->\n" +
+		"### Stack\n" +
+		"\n" +
+		"- #3 `feature-3`\n" +
+		"- **#2 `feature-2`** ← this pull request\n" +
+		"- #1 `feature-1`\n" +
+		"- `main`\n" +
+		"<!-- /lazygithub:stack -->"
+	if got := stackSection(pullRequests, "main", 1); got != want {
+		t.Errorf("stackSection() = %q, want %q", got, want)
+	}
+}
+
+func Test_withStackSection(t *testing.T) {
+	section := "<!-- lazygithub:stack -->\nnew\n<!-- /lazygithub:stack -->"
+
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{
+			name: "empty body",
+			body: "",
+			want: section,
+		},
+		{
+			name: "appended after the body",
+			body: "Summary\n\n",
+			want: "Summary\n\n" + section,
+		},
+		{
+			name: "replaces the section of an earlier run",
+			body: "Summary\n\n<!-- lazygithub:stack -->\nold\n<!-- /lazygithub:stack -->\n\nFooter",
+			want: "Summary\n\n" + section + "\n\nFooter",
+		},
+		{
+			name: "unchanged section",
+			body: "Summary\n\n" + section,
+			want: "Summary\n\n" + section,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := withStackSection(tt.body, section); got != tt.want {
+				t.Errorf("withStackSection() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_withoutStackSection(t *testing.T) {
+	section := "<!-- lazygithub:stack -->\nold\n<!-- /lazygithub:stack -->"
+
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{name: "only the section", body: section, want: ""},
+		{name: "after the body", body: "Summary\n\n" + section, want: "Summary"},
+		{name: "between the body and a footer", body: "Summary\n\n" + section + "\n\nFooter", want: "Summary\n\nFooter"},
+		{name: "no section", body: "Summary", want: "Summary"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := withoutStackSection(tt.body); got != tt.want {
+				t.Errorf("withoutStackSection() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStackPullRequests_updateStackSections(t *testing.T) {
+	// feature-2 (#2) was dropped from the stack after a rebase, so feature-3 is now based on feature-1
+	oldSection := func(i int) string {
+		return stackSection([]gh_command.PullRequest{
+			{Number: 1, HeadRefName: "feature-1"},
+			{Number: 2, HeadRefName: "feature-2"},
+			{Number: 3, HeadRefName: "feature-3"},
+		}, "main", i)
+	}
+	runner := command_runner.NewFakeRunner().
+		Add(
+			command_runner.FakeResponse{Stdout: `{"data": {"updatePullRequest": {"clientMutationId": null}}}`},
+			"gh", "api", "graphql", "--hostname", "github.example.com", "--input", "-",
+		).
+		Add(
+			command_runner.FakeResponse{Stdout: `{"id": "PR_2", "number": 2, "body": ` + strconv.Quote("Second\n\n"+oldSection(1)) + `}`},
+			"gh", "pr", "view", "2", "--repo", "github.example.com/owner/repo",
+			"--json", "id,number,url,title,state,isDraft,headRefName,baseRefName,author,body",
+		)
+	defer command_runner.SetDefault(runner)()
+
+	s := &StackPullRequests{
+		repoHost:   "github.example.com",
+		repo:       "github.example.com/owner/repo",
+		stack:      []string{"feature-1", "feature-3"},
+		baseBranch: "main",
+		pullRequests: []gh_command.PullRequest{
+			{ID: "PR_1", Number: 1, HeadRefName: "feature-1", Body: "First\n\n" + oldSection(0)},
+			{ID: "PR_3", Number: 3, HeadRefName: "feature-3", Body: "Third\n\n" + oldSection(2)},
+		},
+	}
+	if err := s.updateStackSections(context.Background()); err != nil {
+		t.Fatalf("StackPullRequests.updateStackSections() error = %v", err)
+	}
+
+	requests := graphqlCalls(t, runner)
+	if len(requests) != 3 {
+		t.Fatalf("StackPullRequests.updateStackSections() sent %d GraphQL requests, want 3", len(requests))
+	}
+	if body, _ := requests[0].Variables.Input["body"].(string); strings.Contains(body, "feature-2") {
+		t.Errorf("body of #1 = %q, want the stack without feature-2", body)
+	}
+	if got := requests[2].Variables.Input; got["pullRequestId"] != "PR_2" || got["body"] != "Second" {
+		t.Errorf("updatePullRequest input = %v, want the stack section removed from #2", got)
+	}
+}
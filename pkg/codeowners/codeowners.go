@@ -0,0 +1,228 @@
+package codeowners
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Locations are the paths GitHub looks for the CODEOWNERS file at, relative to the repository root.
+// Only the first file found is used.
+var Locations = []string{
+	filepath.Join(".github", "CODEOWNERS"),
+	"CODEOWNERS",
+	filepath.Join("docs", "CODEOWNERS"),
+}
+
+// Rule struct to represent a line of the CODEOWNERS file
+type Rule struct {
+	Pattern string
+	// Owners are "@user", "@org/team" or email addresses, empty when the pattern removes the ownership
+	Owners []string
+	regexp *regexp.Regexp
+}
+
+// Match method to check whether the rule applies to a path relative to the repository root
+func (r Rule) Match(path string) bool {
+	return r.regexp.MatchString(strings.TrimPrefix(filepath.ToSlash(path), "/"))
+}
+
+// Ruleset struct to represent the rules of a CODEOWNERS file in the order they are written
+type Ruleset struct {
+	Rules []Rule
+	// Errors describe the invalid lines that were skipped, like GitHub skips them
+	Errors []error
+}
+
+// Owners method to get the owners of a path.
+// Like GitHub, the last matching rule wins, even when it has no owners.
+func (r Ruleset) Owners(path string) []string {
+	for i := len(r.Rules) - 1; i >= 0; i-- {
+		if r.Rules[i].Match(path) {
+			return r.Rules[i].Owners
+		}
+	}
+
+	return nil
+}
+
+// OwnersOf method to get the owners of any of the paths, in the order they are first found
+func (r Ruleset) OwnersOf(paths []string) []string {
+	seen := make(map[string]bool)
+	owners := make([]string, 0)
+	for _, path := range paths {
+		for _, owner := range r.Owners(path) {
+			if !seen[owner] {
+				seen[owner] = true
+				owners = append(owners, owner)
+			}
+		}
+	}
+
+	return owners
+}
+
+// Parse function to parse the content of a CODEOWNERS file.
+// Blank lines and comments are skipped, and so are the invalid lines, which are listed in the errors of the ruleset.
+func Parse(content string) Ruleset {
+	ruleset := Ruleset{Rules: make([]Rule, 0)}
+	for i, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := splitFields(line)
+		pattern := fields[0]
+		owners := make([]string, 0, len(fields)-1)
+		for _, owner := range fields[1:] {
+			if strings.HasPrefix(owner, "#") {
+				break
+			}
+			owners = append(owners, owner)
+		}
+
+		compiled, err := compilePattern(pattern)
+		if err != nil {
+			ruleset.Errors = append(ruleset.Errors, fmt.Errorf("CODEOWNERS line %d: %w", i+1, err))
+			continue
+		}
+		ruleset.Rules = append(ruleset.Rules, Rule{
+			Pattern: pattern,
+			Owners:  owners,
+			regexp:  compiled,
+		})
+	}
+
+	return ruleset
+}
+
+// splitFields function to split a line by whitespace, keeping the whitespace escaped with a backslash
+func splitFields(line string) []string {
+	fields := make([]string, 0)
+	var field strings.Builder
+	escaped := false
+	for _, r := range line {
+		switch {
+		case escaped:
+			if r != ' ' && r != '\t' {
+				field.WriteRune('\\')
+			}
+			field.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == ' ' || r == '\t':
+			if field.Len() > 0 {
+				fields = append(fields, field.String())
+				field.Reset()
+			}
+		default:
+			field.WriteRune(r)
+		}
+	}
+	if escaped {
+		field.WriteRune('\\')
+	}
+	if field.Len() > 0 {
+		fields = append(fields, field.String())
+	}
+
+	return fields
+}
+
+// compilePattern function to convert a CODEOWNERS pattern into a regular expression matching paths.
+// The patterns follow the gitignore rules GitHub supports: "!" negation and "[ ]" ranges are not supported,
+// and a pattern ending with "/*" only matches the files directly inside the directory.
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if strings.HasPrefix(pattern, "!") {
+		return nil, fmt.Errorf("negated pattern %q is not supported", pattern)
+	}
+	if strings.ContainsAny(pattern, "[]") {
+		return nil, fmt.Errorf("character range in pattern %q is not supported", pattern)
+	}
+
+	dirOnly := strings.HasSuffix(pattern, "/")
+	trimmed := strings.Trim(pattern, "/")
+	if trimmed == "" {
+		return nil, fmt.Errorf("empty pattern %q", pattern)
+	}
+	// A pattern with a slash at the start or in the middle is relative to the root, otherwise it matches at any depth
+	anchored := strings.HasPrefix(pattern, "/") || strings.Contains(trimmed, "/")
+
+	var expr strings.Builder
+	expr.WriteString("^")
+	if !anchored {
+		expr.WriteString("(?:.*/)?")
+	}
+
+	segments := strings.Split(trimmed, "/")
+	for i, segment := range segments {
+		last := i == len(segments)-1
+		if segment == "**" {
+			switch {
+			case last && i == 0:
+				expr.WriteString(".*")
+			case last:
+				// "a/**" matches everything inside a
+				expr.WriteString(".*")
+			default:
+				// "**/" matches zero or more directories
+				expr.WriteString("(?:.*/)?")
+			}
+			continue
+		}
+
+		for _, r := range segment {
+			switch r {
+			case '*':
+				expr.WriteString("[^/]*")
+			case '?':
+				expr.WriteString("[^/]")
+			default:
+				expr.WriteString(regexp.QuoteMeta(string(r)))
+			}
+		}
+		if !last {
+			expr.WriteString("/")
+		}
+	}
+
+	lastSegment := segments[len(segments)-1]
+	switch {
+	case lastSegment == "**":
+		// Everything below is already matched
+	case dirOnly:
+		// A directory pattern matches everything inside the directory
+		expr.WriteString("/.*")
+	case lastSegment == "*" && len(segments) > 1:
+		// "docs/*" matches docs/a.md but not docs/build/a.md
+	default:
+		// The pattern matches a file, or a directory with everything inside it
+		expr.WriteString("(?:/.*)?")
+	}
+	expr.WriteString("$")
+
+	return regexp.Compile(expr.String())
+}
+
+// Find function to read the CODEOWNERS file of the repository at root.
+// It returns the path of the file relative to root, or "" when the repository has none.
+func Find(root string) (Ruleset, string, error) {
+	for _, location := range Locations {
+		content, err := os.ReadFile(filepath.Join(root, location))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return Ruleset{}, "", err
+		}
+
+		return Parse(string(content)), location, nil
+	}
+
+	return Ruleset{}, "", nil
+}
@@ -0,0 +1,182 @@
+package codeowners
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// githubExample is the example CODEOWNERS file of the GitHub documentation
+const githubExample = `# This is a comment.
+# Each line is a file pattern followed by one or more owners.
+
+# These owners will be the default owners for everything in
+# the repo. Unless a later match takes precedence,
+# @global-owner1 and @global-owner2 will be requested for
+# review when someone opens a pull request.
+*       @global-owner1 @global-owner2
+
+# Order is important; the last matching pattern takes the most
+# precedence. When someone opens a pull request that only
+# modifies JS files, only @js-owner and not the global
+# owner(s) will be requested for a review.
+*.js    @js-owner #This is an inline comment.
+
+# You can also use email addresses if you prefer.
+*.go docs@example.com
+
+# Teams can be specified as code owners as well.
+*.txt @octo-org/octocats
+
+# In this example, @doctocat owns any files in the build/logs
+# directory at the root of the repository and any of its
+# subdirectories.
+/build/logs/ @doctocat
+
+# The 'docs/*' pattern will match files like
+# 'docs/getting-started.md' but not further nested files like
+# 'docs/build-app/troubleshooting.md'.
+docs/* docs@example.com
+
+# In this example, @octocat owns any file in an apps directory
+# anywhere in your repository.
+apps/ @octocat
+
+# In this example, @doctocat owns any file in the '/docs'
+# directory in the root of your repository and any of its
+# subdirectories.
+/docs/ @doctocat
+
+# In this example, any change inside the '/scripts' directory
+# will require approval from @doctocat or @octocat.
+/scripts/ @doctocat @octocat
+
+# In this example, @octocat owns any file in a '/logs' directory such as
+# '/build/logs', '/scripts/logs', and '/deeply/nested/logs'. Any changes
+# in a '/logs' directory will require approval from @octocat.
+**/logs @octocat
+
+# In this example, @octocat owns any file in the '/apps'
+# directory in the root of your repository except for the '/apps/github'
+# subdirectory, as this subdirectory has its own owner @doctocat
+/apps/ @octocat
+/apps/github @doctocat
+
+# In this example, @octocat owns any file in the '/apps'
+# directory in the root of your repository except for the '/apps/github'
+# subdirectory, as this subdirectory has no owners
+/apps/ @octocat
+/apps/github
+`
+
+func TestRuleset_Owners(t *testing.T) {
+	ruleset := Parse(githubExample)
+	if len(ruleset.Errors) > 0 {
+		t.Fatalf("Parse() errors = %v", ruleset.Errors)
+	}
+
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{path: "README.md", want: []string{"@global-owner1", "@global-owner2"}},
+		{path: "src/index.js", want: []string{"@js-owner"}},
+		{path: "main.go", want: []string{"docs@example.com"}},
+		{path: "notes/todo.txt", want: []string{"@octo-org/octocats"}},
+		{path: "build/logs/today/error.log", want: []string{"@octocat"}},
+		{path: "docs/getting-started.md", want: []string{"@doctocat"}},
+		{path: "docs/build-app/troubleshooting.md", want: []string{"@doctocat"}},
+		{path: "web/apps/main.py", want: []string{"@octocat"}},
+		{path: "scripts/deploy.sh", want: []string{"@doctocat", "@octocat"}},
+		{path: "deeply/nested/logs/a.log", want: []string{"@octocat"}},
+		{path: "apps/github/index.html", want: []string{}},
+		{path: "apps/web/index.html", want: []string{"@octocat"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := ruleset.Owners(tt.path); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Ruleset.Owners() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRule_Match(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{pattern: "docs/*", path: "docs/getting-started.md", want: true},
+		{pattern: "docs/*", path: "docs/build-app/troubleshooting.md", want: false},
+		{pattern: "docs/*", path: "web/docs/a.md", want: false},
+		{pattern: "/docs/", path: "web/docs/a.md", want: false},
+		{pattern: "apps/", path: "apps", want: false},
+		{pattern: "apps/", path: "a/b/apps/c/d.go", want: true},
+		{pattern: "README.md", path: "sub/README.md", want: true},
+		{pattern: "/README.md", path: "sub/README.md", want: false},
+		{pattern: "config", path: "pkg/config/config.go", want: true},
+		{pattern: "pkg/config", path: "pkg/config/config.go", want: true},
+		{pattern: "pkg/config", path: "other/pkg/config/config.go", want: false},
+		{pattern: "pkg/**/test", path: "pkg/test/a.go", want: true},
+		{pattern: "pkg/**/test", path: "pkg/a/b/test/a.go", want: true},
+		{pattern: "pkg/**", path: "pkg/a/b.go", want: true},
+		{pattern: "**", path: "a/b.go", want: true},
+		{pattern: "*.go", path: "a/b.go", want: true},
+		{pattern: "?.go", path: "ab.go", want: false},
+		{pattern: "a.go", path: "xa.go", want: false},
+		{pattern: `my\ file.txt`, path: "my file.txt", want: true},
+		// GitHub does not support escaping a leading "#"
+		{pattern: `\#notes`, path: "#notes", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.path, func(t *testing.T) {
+			ruleset := Parse(tt.pattern + " @owner")
+			if len(ruleset.Errors) > 0 {
+				t.Fatalf("Parse() errors = %v", ruleset.Errors)
+			}
+			if got := ruleset.Rules[0].Match(tt.path); got != tt.want {
+				t.Errorf("Rule.Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse_unsupported(t *testing.T) {
+	ruleset := Parse("* @everyone\n!docs/ @owner\n[abc].go @owner\n*.go @gophers")
+	if len(ruleset.Errors) != 2 {
+		t.Errorf("Parse() errors = %v, want the two unsupported lines", ruleset.Errors)
+	}
+	if got := ruleset.OwnersOf([]string{"a.go", "docs/a.md"}); !reflect.DeepEqual(got, []string{"@gophers", "@everyone"}) {
+		t.Errorf("Ruleset.OwnersOf() = %v, want the remaining rules to still apply", got)
+	}
+}
+
+func TestFind(t *testing.T) {
+	root := t.TempDir()
+	for path, content := range map[string]string{
+		filepath.Join("docs", "CODEOWNERS"):    "* @docs",
+		filepath.Join(".github", "CODEOWNERS"): "* @github",
+	} {
+		if err := os.MkdirAll(filepath.Join(root, filepath.Dir(path)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(root, path), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ruleset, path, err := Find(root)
+	if err != nil || path != filepath.Join(".github", "CODEOWNERS") {
+		t.Fatalf("Find() = %v, %v, want the .github file first", path, err)
+	}
+	if got := ruleset.OwnersOf([]string{"a.go", "b.go"}); !reflect.DeepEqual(got, []string{"@github"}) {
+		t.Errorf("Ruleset.OwnersOf() = %v, want [@github]", got)
+	}
+
+	_, path, err = Find(t.TempDir())
+	if err != nil || path != "" {
+		t.Errorf("Find() = %v, %v, want no file", path, err)
+	}
+}
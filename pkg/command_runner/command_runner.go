@@ -0,0 +1,115 @@
+package command_runner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Result struct to represent what a finished command printed and how it exited
+type Result struct {
+	Stdout   []byte
+	Stderr   []byte
+	ExitCode int
+}
+
+// Runner interface to run external commands, so that gh and git can be faked in tests
+type Runner interface {
+	// Run runs the command and returns an error only when it could not be started or the context was done
+	Run(ctx context.Context, name string, args []string, stdin []byte) (Result, error)
+}
+
+// ExecRunner struct to run commands with os/exec
+type ExecRunner struct{}
+
+// waitDelay is how long a cancelled command may take to release its output before it is given up on
+const waitDelay = 3 * time.Second
+
+// Run method to run the command as a child process, the process is killed when the context is done
+func (ExecRunner) Run(ctx context.Context, name string, args []string, stdin []byte) (Result, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.WaitDelay = waitDelay
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	result := Result{Stdout: stdout.Bytes(), Stderr: stderr.Bytes()}
+	if ctx.Err() != nil {
+		return result, ctx.Err()
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		result.ExitCode = exitErr.ExitCode()
+		return result, nil
+	}
+
+	return result, err
+}
+
+// Default is the runner used by gh_command and git_command
+var Default Runner = ExecRunner{}
+
+// SetDefault function to replace the default runner, it returns a function restoring the previous one
+func SetDefault(runner Runner) func() {
+	previous := Default
+	Default = runner
+
+	return func() {
+		Default = previous
+	}
+}
+
+// Output function to run a command with the default runner and return its stdout.
+// The stdout is also returned when the command fails, since gh prints error responses to it.
+func Output(ctx context.Context, name string, args []string, stdin []byte) ([]byte, error) {
+	result, err := Default.Run(ctx, name, args, stdin)
+	if err == nil && result.ExitCode != 0 {
+		err = fmt.Errorf("exit status %d", result.ExitCode)
+	}
+	if err != nil {
+		exitCode := result.ExitCode
+		if exitCode == 0 {
+			exitCode = -1
+		}
+
+		return result.Stdout, &CommandError{
+			Name:     name,
+			Args:     args,
+			Stderr:   strings.TrimSpace(string(result.Stderr)),
+			ExitCode: exitCode,
+			Kind:     classify(err, string(result.Stderr)),
+			Err:      err,
+		}
+	}
+
+	return result.Stdout, nil
+}
+
+// OutputJSON function to run a command and decode its JSON stdout into out
+func OutputJSON(ctx context.Context, name string, args []string, stdin []byte, out interface{}) error {
+	output, err := Output(ctx, name, args, stdin)
+	if err != nil {
+		return err
+	}
+
+	return DecodeJSON(name, args, output, out)
+}
+
+// DecodeJSON function to decode the JSON output of a command, reporting failures as a DecodeError
+func DecodeJSON(name string, args []string, output []byte, out interface{}) error {
+	if err := json.Unmarshal(output, out); err != nil {
+		return &DecodeError{Name: name, Args: args, Output: string(output), Err: err}
+	}
+
+	return nil
+}
@@ -0,0 +1,125 @@
+package command_runner
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+func TestOutput(t *testing.T) {
+	tests := []struct {
+		name     string
+		response FakeResponse
+		want     string
+		wantKind error
+		wantErr  bool
+	}{
+		{
+			name:     "success",
+			response: FakeResponse{Stdout: "output"},
+			want:     "output",
+		},
+		{
+			name:     "not installed",
+			response: FakeResponse{Err: &exec.Error{Name: "gh", Err: exec.ErrNotFound}},
+			wantKind: ErrNotInstalled,
+			wantErr:  true,
+		},
+		{
+			name:     "not authenticated",
+			response: FakeResponse{Stderr: "To get started with GitHub CLI, please run:  gh auth login", ExitCode: 4},
+			wantKind: ErrNotAuthenticated,
+			wantErr:  true,
+		},
+		{
+			name:     "not a git repository",
+			response: FakeResponse{Stderr: "fatal: not a git repository (or any of the parent directories): .git", ExitCode: 128},
+			wantKind: ErrNotGitRepository,
+			wantErr:  true,
+		},
+		{
+			name:     "rate limited",
+			response: FakeResponse{Stderr: "gh: API rate limit exceeded for user ID 1. (HTTP 403)", ExitCode: 1},
+			wantKind: ErrRateLimited,
+			wantErr:  true,
+		},
+		{
+			name: "missing scopes",
+			response: FakeResponse{
+				Stderr:   `gh: This API operation needs the "read:org" scope. To request it, run:  gh auth refresh -h github.com -s read:org`,
+				ExitCode: 1,
+			},
+			wantKind: ErrMissingScopes,
+			wantErr:  true,
+		},
+		{
+			name:     "stdout is kept on failure",
+			response: FakeResponse{Stdout: `{"errors": []}`, Stderr: "gh: Something went wrong", ExitCode: 1},
+			want:     `{"errors": []}`,
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer SetDefault(NewFakeRunner().Add(tt.response, "gh", "api", "user"))()
+
+			got, err := Output(context.Background(), "gh", []string{"api", "user"}, nil)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Output() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if string(got) != tt.want {
+				t.Errorf("Output() = %v, want %v", string(got), tt.want)
+			}
+			if tt.wantKind != nil && !errors.Is(err, tt.wantKind) {
+				t.Errorf("Output() error = %v, want kind %v", err, tt.wantKind)
+			}
+			var commandErr *CommandError
+			if tt.wantErr && (!errors.As(err, &commandErr) || commandErr.Stderr != tt.response.Stderr) {
+				t.Errorf("Output() error = %#v, want a CommandError with stderr %q", err, tt.response.Stderr)
+			}
+		})
+	}
+}
+
+func TestOutputJSON(t *testing.T) {
+	defer SetDefault(NewFakeRunner().Add(FakeResponse{Stdout: "not json"}, "gh", "api", "user"))()
+
+	var out struct{}
+	err := OutputJSON(context.Background(), "gh", []string{"api", "user"}, nil, &out)
+	if !errors.Is(err, ErrJSONDecode) {
+		t.Errorf("OutputJSON() error = %v, want %v", err, ErrJSONDecode)
+	}
+}
+
+func TestExecRunner_Run_cancel(t *testing.T) {
+	if _, err := exec.LookPath("sleep"); err != nil {
+		t.Skip("sleep is not available")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := ExecRunner{}.Run(ctx, "sleep", []string{"10"}, nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("ExecRunner.Run() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("ExecRunner.Run() returned after %s, want the process to be killed", elapsed)
+	}
+}
+
+func TestOutput_cancelled(t *testing.T) {
+	defer SetDefault(NewFakeRunner().Add(FakeResponse{Stdout: "output"}, "gh", "api", "user"))()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := Output(ctx, "gh", []string{"api", "user"}, nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Output() error = %v, want %v", err, context.Canceled)
+	}
+}
@@ -0,0 +1,105 @@
+package command_runner
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// The kinds of failures that callers can tell apart with errors.Is
+var (
+	ErrNotInstalled     = errors.New("command is not installed")
+	ErrNotAuthenticated = errors.New("not authenticated")
+	ErrNotGitRepository = errors.New("not a git repository")
+	ErrRepoNotFound     = errors.New("repository not found")
+	ErrNotFound         = errors.New("not found")
+	ErrRateLimited      = errors.New("rate limited")
+	ErrMissingScopes    = errors.New("the token is missing scopes")
+	ErrJSONDecode       = errors.New("failed to decode JSON")
+)
+
+// CommandError struct to represent an external command that could not be run or exited with an error
+type CommandError struct {
+	Name     string
+	Args     []string
+	Stderr   string
+	ExitCode int
+	// Kind is one of the Err* kinds above, or nil when the failure is not recognized
+	Kind error
+	Err  error
+}
+
+// Error method to describe the failed command
+func (e *CommandError) Error() string {
+	message := fmt.Sprintf("%s %s failed", e.Name, strings.Join(e.Args, " "))
+	if e.Kind != nil {
+		message += ": " + e.Kind.Error()
+	}
+
+	return message + ": " + e.Err.Error()
+}
+
+// Unwrap method to expose both the kind and the underlying error to errors.Is and errors.As
+func (e *CommandError) Unwrap() []error {
+	if e.Kind == nil {
+		return []error{e.Err}
+	}
+
+	return []error{e.Kind, e.Err}
+}
+
+// DecodeError struct to represent output of a command that could not be decoded
+type DecodeError struct {
+	Name   string
+	Args   []string
+	Output string
+	Err    error
+}
+
+// Error method to describe the output that could not be decoded
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("failed to decode the output of %s %s: %s", e.Name, strings.Join(e.Args, " "), e.Err)
+}
+
+// Unwrap method to expose the kind and the underlying error to errors.Is and errors.As
+func (e *DecodeError) Unwrap() []error {
+	return []error{ErrJSONDecode, e.Err}
+}
+
+// stderrKinds maps messages printed by gh and git to the kind of failure they mean.
+// The first match wins, so the more specific messages come first.
+var stderrKinds = []struct {
+	message string
+	kind    error
+}{
+	{"not a git repository", ErrNotGitRepository},
+	{"gh auth login", ErrNotAuthenticated},
+	{"authentication required", ErrNotAuthenticated},
+	{"bad credentials", ErrNotAuthenticated},
+	{"http 401", ErrNotAuthenticated},
+	{"rate limit", ErrRateLimited},
+	{"http 429", ErrRateLimited},
+	// gh suggests adding the scope a REST request lacks, e.g. "needs the "read:org" scope. To request it, run: gh auth refresh"
+	{"gh auth refresh", ErrMissingScopes},
+	{"could not resolve to a repository", ErrRepoNotFound},
+	{"none of the git remotes configured for this repository point to a known github host", ErrRepoNotFound},
+	{"no git remotes found", ErrRepoNotFound},
+	{"http 404", ErrNotFound},
+}
+
+// classify function to find out the kind of failure from the error and the stderr of a command
+func classify(err error, stderr string) error {
+	if errors.Is(err, exec.ErrNotFound) {
+		return ErrNotInstalled
+	}
+
+	lowerStderr := strings.ToLower(stderr)
+	for _, stderrKind := range stderrKinds {
+		if strings.Contains(lowerStderr, stderrKind.message) {
+			return stderrKind.kind
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,100 @@
+package command_runner
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// FakeResponse struct to represent the canned outcome of a faked command
+type FakeResponse struct {
+	Stdout   string
+	Stderr   string
+	ExitCode int
+	// Err is returned when the command should fail to start, e.g. exec.ErrNotFound
+	Err error
+}
+
+// FakeCall struct to represent a command that was run through the FakeRunner
+type FakeCall struct {
+	Name  string
+	Args  []string
+	Stdin string
+}
+
+// CommandLine method to get the command line of the call joined by spaces
+func (c FakeCall) CommandLine() string {
+	return commandLine(c.Name, c.Args)
+}
+
+// FakeRunner struct to return canned responses instead of running commands, recording every call.
+// Responses are looked up by the command line joined by spaces. When several responses are
+// added for the same command line they are returned in order, and the last one is repeated.
+type FakeRunner struct {
+	mu        sync.Mutex
+	responses map[string][]FakeResponse
+	calls     []FakeCall
+}
+
+// NewFakeRunner function to create a FakeRunner without any responses
+func NewFakeRunner() *FakeRunner {
+	return &FakeRunner{responses: make(map[string][]FakeResponse)}
+}
+
+// commandLine function to join the command name and its arguments by spaces
+func commandLine(name string, args []string) string {
+	return strings.Join(append([]string{name}, args...), " ")
+}
+
+// Add method to add a response for the command line made of name and args
+func (f *FakeRunner) Add(response FakeResponse, name string, args ...string) *FakeRunner {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	key := commandLine(name, args)
+	f.responses[key] = append(f.responses[key], response)
+
+	return f
+}
+
+// Calls method to get the commands that were run so far
+func (f *FakeRunner) Calls() []FakeCall {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]FakeCall{}, f.calls...)
+}
+
+// Run method to record the call and return the next response for its command line.
+// Commands without a response exit with 127 like a shell would for an unknown command,
+// and commands run with a done context fail with its error like a killed process would.
+func (f *FakeRunner) Run(ctx context.Context, name string, args []string, stdin []byte) (Result, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	key := commandLine(name, args)
+	f.calls = append(f.calls, FakeCall{Name: name, Args: args, Stdin: string(stdin)})
+	if ctx.Err() != nil {
+		return Result{}, ctx.Err()
+	}
+
+	responses := f.responses[key]
+	if len(responses) == 0 {
+		return Result{
+			Stderr:   []byte(fmt.Sprintf("fake runner: no response for %q", key)),
+			ExitCode: 127,
+		}, nil
+	}
+
+	response := responses[0]
+	if len(responses) > 1 {
+		f.responses[key] = responses[1:]
+	}
+
+	return Result{
+		Stdout:   []byte(response.Stdout),
+		Stderr:   []byte(response.Stderr),
+		ExitCode: response.ExitCode,
+	}, response.Err
+}
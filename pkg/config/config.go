@@ -0,0 +1,336 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// configPathEnv is the environment variable that overrides the location of the config file
+const configPathEnv = "LAZYGITHUB_CONFIG"
+
+// The backends that can send the requests to the GitHub API
+const (
+	// BackendGH sends the requests through the gh CLI, it is the default
+	BackendGH = "gh"
+	// BackendHTTP sends the requests over HTTP without needing gh
+	BackendHTTP = "http"
+)
+
+// defaultPullRequestTemplateHeading is the heading the prepopulated content is appended under
+const defaultPullRequestTemplateHeading = "Changes"
+
+// PullRequestTemplateConfig struct to represent how the repository pull request templates are used
+type PullRequestTemplateConfig struct {
+	// Heading is the section the prepopulated content is appended under,
+	// when the template has no placeholders for it
+	Heading string `json:"heading"`
+	// Enabled defaults to true when it is omitted
+	Enabled *bool `json:"enabled"`
+}
+
+// IsEnabled method to check whether the pull request templates are used
+func (t PullRequestTemplateConfig) IsEnabled() bool {
+	return t.Enabled == nil || *t.Enabled
+}
+
+// The ways the reviewers are preselected in the reviewer picker
+const (
+	// PreselectLatest preselects the reviewers chosen the last time, it is the default
+	PreselectLatest = "latest"
+	// PreselectSuggested preselects the top suggested reviewers
+	PreselectSuggested = "suggested"
+	// PreselectNone preselects nobody
+	PreselectNone = "none"
+)
+
+// The built-in templates the pull request body is made from the commits with
+const (
+	// BodyPresetFull lists the full message of every commit, it is the default
+	BodyPresetFull = "full"
+	// BodyPresetChangelog lists the summary of every commit as a bullet
+	BodyPresetChangelog = "changelog"
+	// BodyPresetGrouped lists the commits as bullets under a heading for each Conventional Commit type
+	BodyPresetGrouped = "grouped"
+)
+
+// defaultReviewerSuggestions is how many of the top ranked reviewers are marked as suggested
+const defaultReviewerSuggestions = 3
+
+// defaultReviewerDecayDays is the half-life of a past reviewer choice in days,
+// long enough for the regular reviewers to stay on top and short enough for a new one to catch up
+const defaultReviewerDecayDays = 30
+
+// ReviewersConfig struct to represent how the reviewer suggestions are ranked and preselected
+type ReviewersConfig struct {
+	// Suggestions is how many of the top ranked reviewers are marked as suggested, defaulting to 3
+	Suggestions *int `json:"suggestions"`
+	// DecayDays is the half-life of a past choice in days, defaulting to 30, 0 keeps every choice at full weight
+	DecayDays *float64 `json:"decayDays"`
+	// Preselect is "latest", "suggested" or "none", defaulting to "latest"
+	Preselect string `json:"preselect"`
+	// PreselectCodeOwners also preselects the code owners of the changed files
+	PreselectCodeOwners bool `json:"preselectCodeOwners"`
+}
+
+// SuggestionCount method to get how many of the top ranked reviewers are marked as suggested
+func (r ReviewersConfig) SuggestionCount() int {
+	if r.Suggestions == nil {
+		return defaultReviewerSuggestions
+	}
+
+	return *r.Suggestions
+}
+
+// HalfLife method to get the time after which a past choice counts half, 0 when choices do not decay
+func (r ReviewersConfig) HalfLife() time.Duration {
+	days := float64(defaultReviewerDecayDays)
+	if r.DecayDays != nil {
+		days = *r.DecayDays
+	}
+
+	return time.Duration(days * float64(24*time.Hour))
+}
+
+// PreselectMode method to get how the reviewers are preselected
+func (r ReviewersConfig) PreselectMode() string {
+	if r.Preselect == "" {
+		return PreselectLatest
+	}
+
+	return r.Preselect
+}
+
+// TitleConfig struct to represent how the pull request title is suggested
+type TitleConfig struct {
+	// BranchFallback also suggests a title made from the name of the head branch, defaulting to true when it is omitted
+	BranchFallback *bool `json:"branchFallback"`
+}
+
+// IsBranchFallbackEnabled method to check whether a title is suggested from the name of the head branch
+func (t TitleConfig) IsBranchFallbackEnabled() bool {
+	return t.BranchFallback == nil || *t.BranchFallback
+}
+
+// BodyConfig struct to represent how the pull request body is made from the commits
+type BodyConfig struct {
+	// Preset is "full", "changelog" or "grouped", defaulting to "full"
+	Preset string `json:"preset"`
+	// TemplateFile is a Go text/template file used instead of the preset,
+	// a relative path is resolved from the root of the repository
+	TemplateFile string `json:"templateFile"`
+}
+
+// PresetName method to get the built-in template the body is made with
+func (b BodyConfig) PresetName() string {
+	if b.Preset == "" {
+		return BodyPresetFull
+	}
+
+	return b.Preset
+}
+
+// validate method to check that the preset is a built-in one
+func (b BodyConfig) validate() error {
+	switch b.PresetName() {
+	case BodyPresetFull, BodyPresetChangelog, BodyPresetGrouped:
+		return nil
+	default:
+		return fmt.Errorf(
+			"unknown body preset %q, expected %q, %q or %q",
+			b.Preset,
+			BodyPresetFull,
+			BodyPresetChangelog,
+			BodyPresetGrouped,
+		)
+	}
+}
+
+// RepositoryConfig struct to represent the settings that only apply to one repository
+type RepositoryConfig struct {
+	IssueTrackers       []IssueTrackerRule        `json:"issueTrackers"`
+	PullRequestTemplate PullRequestTemplateConfig `json:"pullRequestTemplate"`
+	Body                BodyConfig                `json:"body"`
+}
+
+// Config struct to represent the lazygithub config file
+type Config struct {
+	// Backend is either "gh" or "http", defaulting to "gh".
+	// The list and view commands always go through gh.
+	Backend string `json:"backend"`
+	// Hostname is the GitHub host used when neither the --hostname flag nor the git remotes decide it.
+	// Like GH_HOST, it lets "owner/name" point at a GitHub Enterprise Server host.
+	Hostname string `json:"hostname"`
+	// IssueTrackers defaults to the Jira rule lazygithub always had when it is omitted
+	IssueTrackers       []IssueTrackerRule        `json:"issueTrackers"`
+	PullRequestTemplate PullRequestTemplateConfig `json:"pullRequestTemplate"`
+	Reviewers           ReviewersConfig           `json:"reviewers"`
+	Title               TitleConfig               `json:"title"`
+	Body                BodyConfig                `json:"body"`
+	// Repositories holds the per repository overrides keyed by "owner/name"
+	Repositories map[string]RepositoryConfig `json:"repositories"`
+}
+
+// Path function to get the path of the config file
+func Path() (string, error) {
+	if path := os.Getenv(configPathEnv); path != "" {
+		return path, nil
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user config directory: %w", err)
+	}
+
+	return filepath.Join(dir, "lazygithub", "config.json"), nil
+}
+
+// Load function to read the config file, a missing file results in an empty config
+func Load() (Config, error) {
+	path, err := Path()
+	if err != nil {
+		return Config{}, err
+	}
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Config{}, nil
+	}
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var c Config
+	if err := json.Unmarshal(content, &c); err != nil {
+		return Config{}, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	if err := c.validate(); err != nil {
+		return Config{}, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+
+	return c, nil
+}
+
+// validate method to check the rules of the config so that mistakes are reported once at startup
+func (c Config) validate() error {
+	if c.Backend != "" && c.Backend != BackendGH && c.Backend != BackendHTTP {
+		return fmt.Errorf("unknown backend %q, expected %q or %q", c.Backend, BackendGH, BackendHTTP)
+	}
+	switch c.Reviewers.PreselectMode() {
+	case PreselectLatest, PreselectSuggested, PreselectNone:
+	default:
+		return fmt.Errorf(
+			"unknown reviewers preselect %q, expected %q, %q or %q",
+			c.Reviewers.Preselect,
+			PreselectLatest,
+			PreselectSuggested,
+			PreselectNone,
+		)
+	}
+	if c.Reviewers.SuggestionCount() < 0 {
+		return fmt.Errorf("reviewers suggestions must not be negative")
+	}
+	if c.Reviewers.HalfLife() < 0 {
+		return fmt.Errorf("reviewers decayDays must not be negative")
+	}
+	if err := c.Body.validate(); err != nil {
+		return err
+	}
+	for _, rule := range c.issueTrackers() {
+		if !rule.IsEnabled() {
+			continue
+		}
+		if _, err := rule.resolve().Compile(); err != nil {
+			return err
+		}
+	}
+	for repo, repoConfig := range c.Repositories {
+		if err := repoConfig.Body.validate(); err != nil {
+			return fmt.Errorf("%s: %w", repo, err)
+		}
+		// An override may only disable or point a global rule elsewhere, so the merged rules are the ones checked
+		owner, name, _ := strings.Cut(repo, "/")
+		for _, rule := range c.IssueTrackersFor("", owner, name) {
+			if _, err := rule.Compile(); err != nil {
+				return fmt.Errorf("%s: %w", repo, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// issueTrackers method to get the global issue tracker rules, the default ones when the config does not list any.
+// An empty list turns the default ones off.
+func (c Config) issueTrackers() []IssueTrackerRule {
+	if c.IssueTrackers == nil {
+		return defaultIssueTrackers
+	}
+
+	return c.IssueTrackers
+}
+
+// IssueTrackersFor method to get the enabled issue tracker rules for a repository on a host, an empty host meaning github.com.
+// A repository rule replaces the global rule with the same name field by field,
+// so that a repository can e.g. only disable or point a global rule elsewhere.
+func (c Config) IssueTrackersFor(host string, owner string, name string) []IssueTrackerRule {
+	rules := make([]IssueTrackerRule, 0, len(c.issueTrackers()))
+	rules = append(rules, c.issueTrackers()...)
+
+	for _, override := range c.Repositories[owner+"/"+name].IssueTrackers {
+		merged := false
+		for i, rule := range rules {
+			if override.Name != "" && rule.Name == override.Name {
+				rules[i] = rule.merge(override)
+				merged = true
+				break
+			}
+		}
+		if !merged {
+			rules = append(rules, override)
+		}
+	}
+
+	enabled := make([]IssueTrackerRule, 0, len(rules))
+	for _, rule := range rules {
+		rule = rule.resolve()
+		if rule.IsEnabled() {
+			rule.URL = expandRepoPlaceholders(rule.URL, host, owner, name)
+			enabled = append(enabled, rule)
+		}
+	}
+
+	return enabled
+}
+
+// PullRequestTemplateFor method to get the pull request template settings for a repository
+func (c Config) PullRequestTemplateFor(owner string, name string) PullRequestTemplateConfig {
+	template := c.PullRequestTemplate
+	override := c.Repositories[owner+"/"+name].PullRequestTemplate
+	if override.Heading != "" {
+		template.Heading = override.Heading
+	}
+	if override.Enabled != nil {
+		template.Enabled = override.Enabled
+	}
+	if template.Heading == "" {
+		template.Heading = defaultPullRequestTemplateHeading
+	}
+
+	return template
+}
+
+// BodyFor method to get how the pull request body is made for a repository.
+// A repository that sets a preset or a template file replaces the global setting as a whole.
+func (c Config) BodyFor(owner string, name string) BodyConfig {
+	override := c.Repositories[owner+"/"+name].Body
+	if override.Preset != "" || override.TemplateFile != "" {
+		return override
+	}
+
+	return c.Body
+}
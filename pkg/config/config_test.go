@@ -0,0 +1,243 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func boolPointer(value bool) *bool {
+	return &value
+}
+
+func TestConfig_IssueTrackersFor(t *testing.T) {
+	type args struct {
+		host  string
+		owner string
+		name  string
+	}
+	tests := []struct {
+		name   string
+		config Config
+		args   args
+		want   []IssueTrackerRule
+	}{
+		{
+			name:   "empty config",
+			config: Config{},
+			args:   args{owner: "owner", name: "repo"},
+			want: []IssueTrackerRule{
+				{
+					Name:    "jira",
+					Preset:  "jira",
+					Pattern: `[A-Z]+-\d+`,
+					URL:     "https://keends.atlassian.net/browse/{key}",
+					Heading: "Jira Link",
+				},
+			},
+		},
+		{
+			name:   "empty list of issue trackers",
+			config: Config{IssueTrackers: []IssueTrackerRule{}},
+			args:   args{owner: "owner", name: "repo"},
+			want:   []IssueTrackerRule{},
+		},
+		{
+			name: "presets fill the empty fields and the repo placeholders are replaced",
+			config: Config{
+				IssueTrackers: []IssueTrackerRule{
+					{Name: "issues", Preset: "github"},
+				},
+			},
+			args: args{owner: "owner", name: "repo"},
+			want: []IssueTrackerRule{
+				{
+					Name:    "issues",
+					Preset:  "github",
+					Pattern: presets["github"].Pattern,
+					URL:     "https://github.com/owner/repo/issues/{key}",
+					Heading: "Related Issues",
+				},
+			},
+		},
+		{
+			name: "the host placeholder points to the GitHub Enterprise Server host",
+			config: Config{
+				IssueTrackers: []IssueTrackerRule{
+					{Name: "issues", Preset: "github"},
+				},
+			},
+			args: args{host: "github.example.com", owner: "owner", name: "repo"},
+			want: []IssueTrackerRule{
+				{
+					Name:    "issues",
+					Preset:  "github",
+					Pattern: presets["github"].Pattern,
+					URL:     "https://github.example.com/owner/repo/issues/{key}",
+					Heading: "Related Issues",
+				},
+			},
+		},
+		{
+			name: "repository overrides replace, disable and add rules",
+			config: Config{
+				IssueTrackers: []IssueTrackerRule{
+					{Name: "jira", Preset: "jira", URL: "https://a.atlassian.net/browse/{key}"},
+					{Name: "issues", Preset: "github"},
+				},
+				Repositories: map[string]RepositoryConfig{
+					"owner/repo": {
+						IssueTrackers: []IssueTrackerRule{
+							{Name: "jira", URL: "https://b.atlassian.net/browse/{key}"},
+							{Name: "issues", Enabled: boolPointer(false)},
+							{Name: "linear", Preset: "linear", Pattern: `ENG-\d+`},
+						},
+					},
+				},
+			},
+			args: args{owner: "owner", name: "repo"},
+			want: []IssueTrackerRule{
+				{
+					Name:    "jira",
+					Preset:  "jira",
+					Pattern: presets["jira"].Pattern,
+					URL:     "https://b.atlassian.net/browse/{key}",
+					Heading: "Jira Link",
+				},
+				{
+					Name:    "linear",
+					Preset:  "linear",
+					Pattern: `ENG-\d+`,
+					URL:     "https://linear.app/issue/{key}",
+					Heading: "Linear Link",
+				},
+			},
+		},
+		{
+			name: "overrides of other repositories are ignored",
+			config: Config{
+				IssueTrackers: []IssueTrackerRule{
+					{Name: "jira", Pattern: `[A-Z]+-\d+`, URL: "https://a.atlassian.net/browse/{key}", Heading: "Jira Link"},
+				},
+				Repositories: map[string]RepositoryConfig{
+					"owner/other": {
+						IssueTrackers: []IssueTrackerRule{
+							{Name: "jira", Enabled: boolPointer(false)},
+						},
+					},
+				},
+			},
+			args: args{owner: "owner", name: "repo"},
+			want: []IssueTrackerRule{
+				{Name: "jira", Pattern: `[A-Z]+-\d+`, URL: "https://a.atlassian.net/browse/{key}", Heading: "Jira Link"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.config.IssueTrackersFor(tt.args.host, tt.args.owner, tt.args.name); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Config.IssueTrackersFor() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{
+			name:    "valid config",
+			content: `{"issueTrackers": [{"name": "jira", "preset": "jira", "url": "https://a.atlassian.net/browse/{key}"}]}`,
+			wantErr: false,
+		},
+		{
+			name:    "invalid pattern",
+			content: `{"issueTrackers": [{"name": "jira", "pattern": "[A-Z"}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "unknown preset",
+			content: `{"repositories": {"owner/repo": {"issueTrackers": [{"name": "x", "preset": "unknown"}]}}}`,
+			wantErr: true,
+		},
+		{
+			name:    "repository override only disabling a rule",
+			content: `{"repositories": {"owner/repo": {"issueTrackers": [{"name": "jira", "enabled": false}]}}}`,
+			wantErr: false,
+		},
+		{
+			name:    "repository override only pointing a rule elsewhere",
+			content: `{"issueTrackers": [{"name": "jira", "preset": "jira"}], "repositories": {"owner/repo": {"issueTrackers": [{"name": "jira", "url": "https://b.atlassian.net/browse/{key}"}]}}}`,
+			wantErr: false,
+		},
+		{
+			name:    "repository rule without pattern",
+			content: `{"repositories": {"owner/repo": {"issueTrackers": [{"name": "other", "url": "https://example.com/{key}"}]}}}`,
+			wantErr: true,
+		},
+		{
+			name:    "reviewer suggestions",
+			content: `{"reviewers": {"suggestions": 5, "decayDays": 30, "preselect": "suggested"}}`,
+			wantErr: false,
+		},
+		{
+			name:    "unknown reviewers preselect",
+			content: `{"reviewers": {"preselect": "everybody"}}`,
+			wantErr: true,
+		},
+		{
+			name:    "negative decay",
+			content: `{"reviewers": {"decayDays": -1}}`,
+			wantErr: true,
+		},
+		{
+			name:    "body preset",
+			content: `{"body": {"preset": "grouped"}, "repositories": {"owner/repo": {"body": {"templateFile": "body.tmpl"}}}}`,
+			wantErr: false,
+		},
+		{
+			name:    "unknown body preset",
+			content: `{"repositories": {"owner/repo": {"body": {"preset": "poem"}}}}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.json")
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			t.Setenv(configPathEnv, path)
+
+			_, err := Load()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestReviewersConfig_HalfLife(t *testing.T) {
+	zero, week := 0.0, 7.0
+	tests := []struct {
+		name   string
+		config ReviewersConfig
+		want   time.Duration
+	}{
+		{name: "recent choices weigh more by default", config: ReviewersConfig{}, want: 30 * 24 * time.Hour},
+		{name: "decay turned off", config: ReviewersConfig{DecayDays: &zero}, want: 0},
+		{name: "configured half-life", config: ReviewersConfig{DecayDays: &week}, want: 7 * 24 * time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.config.HalfLife(); got != tt.want {
+				t.Errorf("ReviewersConfig.HalfLife() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
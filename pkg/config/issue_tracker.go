@@ -0,0 +1,145 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// IssueTrackerRule struct to represent how issue keys are found in commits and linked in the pull request body
+type IssueTrackerRule struct {
+	// Name identifies the rule so that repositories can override it
+	Name string `json:"name"`
+	// Preset fills the pattern, url and heading that are left empty, one of jira, linear, youtrack or github
+	Preset string `json:"preset"`
+	// Pattern is the regular expression of an issue key.
+	// When it has a group named "key", only that group is used as the key.
+	Pattern string `json:"pattern"`
+	// URL is the link template, "{key}", "{host}", "{owner}" and "{repo}" are replaced,
+	// as well as regular expression groups such as "${1}"
+	URL string `json:"url"`
+	// Heading is the title of the section the links are listed under
+	Heading string `json:"heading"`
+	// Enabled defaults to true when it is omitted
+	Enabled *bool `json:"enabled"`
+}
+
+// presets holds the defaults of the well known issue trackers.
+// The URL of the self hosted ones has to be configured.
+var presets = map[string]IssueTrackerRule{
+	"jira": {
+		Pattern: `\b[A-Z][A-Z0-9]+-\d+\b`,
+		Heading: "Jira Link",
+	},
+	"linear": {
+		Pattern: `\b[A-Z][A-Z0-9]+-\d+\b`,
+		URL:     "https://linear.app/issue/{key}",
+		Heading: "Linear Link",
+	},
+	"youtrack": {
+		Pattern: `\b[A-Z][A-Z0-9]+-\d+\b`,
+		Heading: "YouTrack Link",
+	},
+	"github": {
+		Pattern: `(?:^|[\s(])#(?P<key>\d+)\b`,
+		URL:     "https://{host}/{owner}/{repo}/issues/{key}",
+		Heading: "Related Issues",
+	},
+}
+
+// defaultIssueTrackers are the rules used when the config does not list any,
+// they keep linking the Jira issues like lazygithub did before the rules could be configured
+var defaultIssueTrackers = []IssueTrackerRule{
+	{
+		Name:    "jira",
+		Preset:  "jira",
+		Pattern: `[A-Z]+-\d+`,
+		URL:     "https://keends.atlassian.net/browse/{key}",
+	},
+}
+
+// IsEnabled method to check whether the rule is enabled
+func (r IssueTrackerRule) IsEnabled() bool {
+	return r.Enabled == nil || *r.Enabled
+}
+
+// Compile method to compile the pattern of the rule
+func (r IssueTrackerRule) Compile() (*regexp.Regexp, error) {
+	if _, ok := presets[r.Preset]; r.Preset != "" && !ok {
+		return nil, fmt.Errorf("issue tracker %q has an unknown preset %q", r.Name, r.Preset)
+	}
+	if r.Pattern == "" {
+		return nil, fmt.Errorf("issue tracker %q has no pattern", r.Name)
+	}
+	re, err := regexp.Compile(r.Pattern)
+	if err != nil {
+		return nil, fmt.Errorf("issue tracker %q has an invalid pattern: %w", r.Name, err)
+	}
+
+	return re, nil
+}
+
+// Link method to build the key and URL of a match found by the compiled pattern
+func (r IssueTrackerRule) Link(re *regexp.Regexp, input string, match []int) (string, string) {
+	key := input[match[0]:match[1]]
+	if i := re.SubexpIndex("key"); i >= 0 && match[2*i] >= 0 {
+		key = input[match[2*i]:match[2*i+1]]
+	}
+
+	// Expand the groups first so that "${key}" is not mistaken for the "{key}" placeholder
+	url := strings.ReplaceAll(string(re.ExpandString(nil, r.URL, input, match)), "{key}", key)
+
+	return key, url
+}
+
+// merge method to override the fields of the rule with the non-empty fields of another rule
+func (r IssueTrackerRule) merge(override IssueTrackerRule) IssueTrackerRule {
+	if override.Preset != "" {
+		r.Preset = override.Preset
+	}
+	if override.Pattern != "" {
+		r.Pattern = override.Pattern
+	}
+	if override.URL != "" {
+		r.URL = override.URL
+	}
+	if override.Heading != "" {
+		r.Heading = override.Heading
+	}
+	if override.Enabled != nil {
+		r.Enabled = override.Enabled
+	}
+
+	return r
+}
+
+// resolve method to fill the empty fields of the rule from its preset
+func (r IssueTrackerRule) resolve() IssueTrackerRule {
+	preset, ok := presets[r.Preset]
+	if !ok {
+		return r
+	}
+	if r.Pattern == "" {
+		r.Pattern = preset.Pattern
+	}
+	if r.URL == "" {
+		r.URL = preset.URL
+	}
+	if r.Heading == "" {
+		r.Heading = preset.Heading
+	}
+
+	return r
+}
+
+// defaultHost is the host "{host}" is replaced with when the host of the repository is unknown
+const defaultHost = "github.com"
+
+// expandRepoPlaceholders function to replace the repository placeholders of a URL template
+func expandRepoPlaceholders(url string, host string, owner string, name string) string {
+	if host == "" {
+		host = defaultHost
+	}
+
+	return strings.NewReplacer("{host}", host, "{owner}", owner, "{repo}", name).Replace(url)
+}
@@ -0,0 +1,172 @@
+package gh_api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/coding-for-fun-org/lazygithub/pkg/command_runner"
+)
+
+// defaultHostname is the host of github.com, whose API lives on its own subdomain
+const defaultHostname = "github.com"
+
+// Client struct to send requests to the GitHub REST and GraphQL APIs over HTTP.
+// The same client talks to github.com and GitHub Enterprise Server hosts.
+type Client struct {
+	// Endpoints returns the root of the REST API, ending with a slash, and the GraphQL endpoint of a host
+	Endpoints func(hostname string) (string, string)
+	// Token returns the token used to authenticate on a host
+	Token      func(hostname string) (string, error)
+	HTTPClient *http.Client
+}
+
+// NewClient function to create a client reading the tokens the same way gh does
+func NewClient() *Client {
+	return &Client{
+		Endpoints:  Endpoints,
+		Token:      Token,
+		HTTPClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Endpoints function to get the REST API root and the GraphQL endpoint of github.com or a GitHub Enterprise Server host
+func Endpoints(hostname string) (string, string) {
+	if hostname == "" || strings.EqualFold(hostname, defaultHostname) {
+		return "https://api.github.com/", "https://api.github.com/graphql"
+	}
+
+	return fmt.Sprintf("https://%s/api/v3/", hostname), fmt.Sprintf("https://%s/api/graphql", hostname)
+}
+
+// HTTPError struct to represent a response of the API with an error status
+type HTTPError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Message    string
+	// Kind is one of the command_runner.Err* kinds, or nil when the status is not recognized
+	Kind error
+}
+
+// Error method to describe the failed request
+func (e *HTTPError) Error() string {
+	message := fmt.Sprintf("%s %s failed: HTTP %d", e.Method, e.URL, e.StatusCode)
+	if e.Message != "" {
+		message += ": " + e.Message
+	}
+
+	return message
+}
+
+// Unwrap method to expose the kind of the failure to errors.Is
+func (e *HTTPError) Unwrap() error {
+	return e.Kind
+}
+
+// lacksAcceptedScopes function to check whether the token has none of the scopes the endpoint accepts,
+// which GitHub lists in the X-Accepted-OAuth-Scopes header next to the granted ones in X-OAuth-Scopes
+func lacksAcceptedScopes(header http.Header) bool {
+	accepted := strings.Split(header.Get("X-Accepted-OAuth-Scopes"), ",")
+	granted := strings.Split(header.Get("X-OAuth-Scopes"), ",")
+	for _, scope := range accepted {
+		scope = strings.TrimSpace(scope)
+		if scope == "" {
+			continue
+		}
+		for _, grantedScope := range granted {
+			if strings.TrimSpace(grantedScope) == scope {
+				return false
+			}
+		}
+	}
+
+	return strings.TrimSpace(header.Get("X-Accepted-OAuth-Scopes")) != ""
+}
+
+// newHTTPError function to build the error of a failed response, classifying it like the gh CLI failures
+func newHTTPError(request *http.Request, response *http.Response, body []byte) *HTTPError {
+	var payload struct {
+		Message string `json:"message"`
+	}
+	_ = json.Unmarshal(body, &payload)
+
+	httpErr := &HTTPError{
+		Method:     request.Method,
+		URL:        request.URL.String(),
+		StatusCode: response.StatusCode,
+		Message:    payload.Message,
+	}
+
+	switch {
+	case response.StatusCode == http.StatusUnauthorized:
+		httpErr.Kind = command_runner.ErrNotAuthenticated
+	case response.StatusCode == http.StatusTooManyRequests,
+		response.StatusCode == http.StatusForbidden &&
+			(response.Header.Get("X-RateLimit-Remaining") == "0" ||
+				strings.Contains(strings.ToLower(payload.Message), "rate limit")):
+		httpErr.Kind = command_runner.ErrRateLimited
+	case response.StatusCode == http.StatusForbidden && lacksAcceptedScopes(response.Header):
+		httpErr.Kind = command_runner.ErrMissingScopes
+	case response.StatusCode == http.StatusNotFound:
+		httpErr.Kind = command_runner.ErrNotFound
+	}
+
+	return httpErr
+}
+
+// Request method to send a request to the REST API of the host, or to its GraphQL API when the path is "graphql"
+func (c *Client) Request(ctx context.Context, hostname string, method string, path string, body []byte) ([]byte, error) {
+	if hostname == "" {
+		hostname = defaultHostname
+	}
+	restURL, graphQLURL := c.Endpoints(hostname)
+	url := restURL + strings.TrimPrefix(path, "/")
+	if path == "graphql" {
+		url = graphQLURL
+	}
+
+	token, err := c.Token(hostname)
+	if err != nil {
+		return nil, err
+	}
+
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	request, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Accept", "application/vnd.github+json")
+	request.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	response, err := c.HTTPClient.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s failed: %w", method, url, err)
+	}
+	defer response.Body.Close()
+
+	responseBody, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the response of %s %s: %w", method, url, err)
+	}
+
+	if response.StatusCode >= http.StatusBadRequest {
+		return responseBody, newHTTPError(request, response, responseBody)
+	}
+
+	return responseBody, nil
+}
@@ -0,0 +1,218 @@
+package gh_api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/coding-for-fun-org/lazygithub/pkg/command_runner"
+	"github.com/coding-for-fun-org/lazygithub/pkg/gh_command"
+)
+
+// newTestClient function to create a client talking to a stand-in server for every host.
+// The hosts that were requested are recorded in order.
+func newTestClient(t *testing.T, handler http.HandlerFunc) (*Client, *[]string) {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	hostnames := make([]string, 0)
+	client := NewClient()
+	client.Endpoints = func(hostname string) (string, string) {
+		hostnames = append(hostnames, hostname)
+		return server.URL + "/", server.URL + "/graphql"
+	}
+	client.Token = func(hostname string) (string, error) {
+		return "token", nil
+	}
+	client.HTTPClient = server.Client()
+
+	return client, &hostnames
+}
+
+func TestEndpoints(t *testing.T) {
+	tests := []struct {
+		name           string
+		hostname       string
+		wantRESTURL    string
+		wantGraphQLURL string
+	}{
+		{name: "github.com", hostname: "github.com", wantRESTURL: "https://api.github.com/", wantGraphQLURL: "https://api.github.com/graphql"},
+		{name: "default host", hostname: "", wantRESTURL: "https://api.github.com/", wantGraphQLURL: "https://api.github.com/graphql"},
+		{name: "enterprise server", hostname: "github.example.com", wantRESTURL: "https://github.example.com/api/v3/", wantGraphQLURL: "https://github.example.com/api/graphql"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotRESTURL, gotGraphQLURL := Endpoints(tt.hostname)
+			if gotRESTURL != tt.wantRESTURL || gotGraphQLURL != tt.wantGraphQLURL {
+				t.Errorf("Endpoints() = %s %s, want %s %s", gotRESTURL, gotGraphQLURL, tt.wantRESTURL, tt.wantGraphQLURL)
+			}
+		})
+	}
+}
+
+func TestClient_Request(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       []byte
+		status     int
+		header     map[string]string
+		response   string
+		wantPath   string
+		wantBody   string
+		wantResult string
+		wantKind   error
+		wantErr    bool
+	}{
+		{
+			name:       "REST request",
+			method:     http.MethodGet,
+			path:       "user",
+			status:     http.StatusOK,
+			response:   `{"login": "alice"}`,
+			wantPath:   "/user",
+			wantResult: `{"login": "alice"}`,
+		},
+		{
+			name:       "GraphQL request",
+			method:     http.MethodPost,
+			path:       "graphql",
+			body:       []byte(`{"query": "{ viewer { login } }"}`),
+			status:     http.StatusOK,
+			response:   `{"data": {"viewer": {"login": "alice"}}}`,
+			wantPath:   "/graphql",
+			wantBody:   `{"query": "{ viewer { login } }"}`,
+			wantResult: `{"data": {"viewer": {"login": "alice"}}}`,
+		},
+		{
+			name:     "not authenticated",
+			method:   http.MethodGet,
+			path:     "user",
+			status:   http.StatusUnauthorized,
+			response: `{"message": "Bad credentials"}`,
+			wantPath: "/user",
+			wantKind: command_runner.ErrNotAuthenticated,
+			wantErr:  true,
+		},
+		{
+			name:     "rate limited",
+			method:   http.MethodGet,
+			path:     "user",
+			status:   http.StatusForbidden,
+			header:   map[string]string{"X-RateLimit-Remaining": "0"},
+			response: `{"message": "API rate limit exceeded"}`,
+			wantPath: "/user",
+			wantKind: command_runner.ErrRateLimited,
+			wantErr:  true,
+		},
+		{
+			name:     "missing scopes",
+			method:   http.MethodGet,
+			path:     "repos/owner/repo/teams",
+			status:   http.StatusForbidden,
+			header:   map[string]string{"X-Accepted-OAuth-Scopes": "read:org, repo", "X-OAuth-Scopes": "gist"},
+			response: `{"message": "Resource not accessible by integration"}`,
+			wantPath: "/repos/owner/repo/teams",
+			wantKind: command_runner.ErrMissingScopes,
+			wantErr:  true,
+		},
+		{
+			name:     "not found",
+			method:   http.MethodGet,
+			path:     "repos/owner/repo/compare/main...feature",
+			status:   http.StatusNotFound,
+			response: `{"message": "Not Found"}`,
+			wantPath: "/repos/owner/repo/compare/main...feature",
+			wantKind: command_runner.ErrNotFound,
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != tt.wantPath {
+					t.Errorf("Client.Request() path = %s, want %s", r.URL.Path, tt.wantPath)
+				}
+				if r.Method != tt.method {
+					t.Errorf("Client.Request() method = %s, want %s", r.Method, tt.method)
+				}
+				if got := r.Header.Get("Authorization"); got != "Bearer token" {
+					t.Errorf("Client.Request() Authorization = %s, want Bearer token", got)
+				}
+				body, _ := io.ReadAll(r.Body)
+				if string(body) != tt.wantBody {
+					t.Errorf("Client.Request() body = %s, want %s", body, tt.wantBody)
+				}
+				for key, value := range tt.header {
+					w.Header().Set(key, value)
+				}
+				w.WriteHeader(tt.status)
+				io.WriteString(w, tt.response)
+			})
+
+			got, err := client.Request(context.Background(), "github.com", tt.method, tt.path, tt.body)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Client.Request() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantKind != nil && !errors.Is(err, tt.wantKind) {
+				t.Errorf("Client.Request() error = %v, want kind %v", err, tt.wantKind)
+			}
+			if !tt.wantErr && string(got) != tt.wantResult {
+				t.Errorf("Client.Request() = %s, want %s", got, tt.wantResult)
+			}
+		})
+	}
+}
+
+// TestClient_asGhCommandBackend checks that the gh_command operations work unchanged over HTTP
+func TestClient_asGhCommandBackend(t *testing.T) {
+	client, hostnames := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/user":
+			io.WriteString(w, `{"login": "alice"}`)
+		case "/repos/owner/repo/compare/main...feature":
+			if r.URL.Query().Get("page") != "1" || r.URL.Query().Get("per_page") != "100" {
+				t.Errorf("compare query = %s, want the first page of 100 commits", r.URL.RawQuery)
+			}
+			io.WriteString(w, `{"commits": [{"sha": "abc", "commit": {"message": "feat: login", "author": {"name": "Alice"}}}]}`)
+		case "/graphql":
+			io.WriteString(w, `{"data": {"createPullRequest": {"pullRequest": {"id": "PR_1", "number": 7, "url": "https://github.com/owner/repo/pull/7"}}}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	defer gh_command.SetBackend(client)()
+
+	login, err := gh_command.GetMyUserLogin(context.Background(), "github.example.com")
+	if err != nil || login != "alice" {
+		t.Errorf("GetMyUserLogin() = %v, %v, want alice", login, err)
+	}
+
+	commits, err := gh_command.GetBranchCommits(context.Background(), "github.example.com", "owner", "repo", "main", "feature")
+	wantCommits := []gh_command.Commit{{Sha: "abc", Message: "feat: login", Author: "Alice"}}
+	if err != nil || !reflect.DeepEqual(commits, wantCommits) {
+		t.Errorf("GetBranchCommits() = %v, %v, want %v", commits, err, wantCommits)
+	}
+
+	pullRequest, err := gh_command.CreatePullRequest(context.Background(), gh_command.CreatePullRequestOptions{
+		Hostname: "github.example.com",
+		RepoID:   "R_1",
+	})
+	if err != nil || pullRequest.Number != 7 {
+		t.Errorf("CreatePullRequest() = %v, %v, want pull request 7", pullRequest, err)
+	}
+
+	for _, hostname := range *hostnames {
+		if hostname != "github.example.com" {
+			t.Errorf("requested host = %s, want every request on github.example.com", hostname)
+		}
+	}
+}
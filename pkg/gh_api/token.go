@@ -0,0 +1,115 @@
+package gh_api
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/coding-for-fun-org/lazygithub/pkg/command_runner"
+)
+
+// tokenEnvs returns the environment variables holding a token for the host, in the order gh reads them
+func tokenEnvs(hostname string) []string {
+	if hostname == "" || strings.EqualFold(hostname, defaultHostname) {
+		return []string{"GH_TOKEN", "GITHUB_TOKEN"}
+	}
+
+	return []string{"GH_ENTERPRISE_TOKEN", "GITHUB_ENTERPRISE_TOKEN"}
+}
+
+// ghConfigDir function to get the directory gh keeps its configuration in
+func ghConfigDir() (string, error) {
+	if dir := os.Getenv("GH_CONFIG_DIR"); dir != "" {
+		return dir, nil
+	}
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "gh"), nil
+	}
+	if dir := os.Getenv("AppData"); runtime.GOOS == "windows" && dir != "" {
+		return filepath.Join(dir, "GitHub CLI"), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(home, ".config", "gh"), nil
+}
+
+// parseHostsToken function to find the oauth_token of a host in the hosts.yml of gh.
+// Only the small subset of YAML that gh writes is understood: the token directly under the host.
+func parseHostsToken(content string, hostname string) string {
+	inHost := false
+	hostIndent := -1
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+
+		if indent == 0 {
+			inHost = strings.EqualFold(strings.TrimSuffix(trimmed, ":"), hostname)
+			hostIndent = -1
+			continue
+		}
+		if !inHost {
+			continue
+		}
+
+		// The first indented line sets the indentation of the keys directly under the host
+		if hostIndent < 0 {
+			hostIndent = indent
+		}
+		if indent != hostIndent {
+			continue
+		}
+
+		key, value, found := strings.Cut(trimmed, ":")
+		if found && key == "oauth_token" {
+			return strings.Trim(strings.TrimSpace(value), `"'`)
+		}
+	}
+
+	return ""
+}
+
+// Token function to find the token for a host, from the environment or from the hosts.yml of gh
+func Token(hostname string) (string, error) {
+	if hostname == "" {
+		hostname = defaultHostname
+	}
+
+	for _, env := range tokenEnvs(hostname) {
+		if token := os.Getenv(env); token != "" {
+			return token, nil
+		}
+	}
+
+	dir, err := ghConfigDir()
+	if err != nil {
+		return "", err
+	}
+	content, err := os.ReadFile(filepath.Join(dir, "hosts.yml"))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("failed to read the hosts.yml of gh: %w", err)
+	}
+	if token := parseHostsToken(string(content), hostname); token != "" {
+		return token, nil
+	}
+
+	return "", fmt.Errorf(
+		"no token found for %s, set %s or log in with gh without the keyring: %w",
+		hostname,
+		strings.Join(tokenEnvs(hostname), " or "),
+		command_runner.ErrNotAuthenticated,
+	)
+}
@@ -0,0 +1,81 @@
+package gh_api
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/coding-for-fun-org/lazygithub/pkg/command_runner"
+)
+
+func Test_parseHostsToken(t *testing.T) {
+	content := `github.com:
+    users:
+        alice:
+            oauth_token: gho_user
+    git_protocol: https
+    oauth_token: gho_github
+    user: alice
+github.example.com:
+    oauth_token: "gho_enterprise"
+    user: alice
+`
+	tests := []struct {
+		name     string
+		hostname string
+		want     string
+	}{
+		{name: "token directly under the host", hostname: "github.com", want: "gho_github"},
+		{name: "quoted token", hostname: "github.example.com", want: "gho_enterprise"},
+		{name: "unknown host", hostname: "unknown.example.com", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseHostsToken(content, tt.hostname); got != tt.want {
+				t.Errorf("parseHostsToken() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToken(t *testing.T) {
+	configDir := t.TempDir()
+	hosts := "github.com:\n    oauth_token: gho_hosts\n"
+	if err := os.WriteFile(filepath.Join(configDir, "hosts.yml"), []byte(hosts), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		hostname string
+		env      map[string]string
+		want     string
+		wantKind error
+	}{
+		{name: "GH_TOKEN comes first", hostname: "github.com", env: map[string]string{"GH_TOKEN": "gh", "GITHUB_TOKEN": "github"}, want: "gh"},
+		{name: "GITHUB_TOKEN", hostname: "github.com", env: map[string]string{"GITHUB_TOKEN": "github"}, want: "github"},
+		{name: "hosts.yml", hostname: "github.com", want: "gho_hosts"},
+		{name: "enterprise token", hostname: "github.example.com", env: map[string]string{"GH_TOKEN": "gh", "GH_ENTERPRISE_TOKEN": "enterprise"}, want: "enterprise"},
+		{name: "no token", hostname: "github.example.com", wantKind: command_runner.ErrNotAuthenticated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("GH_CONFIG_DIR", configDir)
+			for _, env := range []string{"GH_TOKEN", "GITHUB_TOKEN", "GH_ENTERPRISE_TOKEN", "GITHUB_ENTERPRISE_TOKEN"} {
+				t.Setenv(env, tt.env[env])
+			}
+
+			got, err := Token(tt.hostname)
+			if tt.wantKind != nil {
+				if !errors.Is(err, tt.wantKind) {
+					t.Errorf("Token() error = %v, want kind %v", err, tt.wantKind)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("Token() = %v, %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package gh_command
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/coding-for-fun-org/lazygithub/pkg/command_runner"
+)
+
+// Backend interface to represent how requests reach the GitHub API
+type Backend interface {
+	// Request sends a request to the API of the host and returns the response body.
+	// The path is relative to the REST API root, "graphql" is the GraphQL endpoint.
+	// When the request fails, the response body is returned along with the error if there is one.
+	Request(ctx context.Context, hostname string, method string, path string, body []byte) ([]byte, error)
+}
+
+// CLIBackend struct to send the requests through `gh api`
+type CLIBackend struct{}
+
+// Request method to send the request with `gh api`
+func (CLIBackend) Request(ctx context.Context, hostname string, method string, path string, body []byte) ([]byte, error) {
+	args := []string{"api", path}
+	if hostname != "" {
+		args = append(args, "--hostname", hostname)
+	}
+	// gh api uses GET without a body and POST with one
+	if (body == nil && method != http.MethodGet) || (body != nil && method != http.MethodPost) {
+		args = append(args, "--method", method)
+	}
+	if body != nil {
+		// Send the body through stdin so that it keeps its JSON types
+		args = append(args, "--input", "-")
+	}
+
+	return command_runner.Output(ctx, "gh", args, body)
+}
+
+// defaultHostname is the host used when it is neither given nor found in the git remotes
+const defaultHostname = "github.com"
+
+// DefaultHostname function to get the host used when it is neither given nor found in the git remotes.
+// Like gh, it can be changed with the GH_HOST environment variable.
+func DefaultHostname() string {
+	if hostname := os.Getenv("GH_HOST"); hostname != "" {
+		return hostname
+	}
+
+	return defaultHostname
+}
+
+// backend is the backend used by the gh_command functions
+var backend Backend = CLIBackend{}
+
+// SetBackend function to replace the backend, it returns a function restoring the previous one
+func SetBackend(b Backend) func() {
+	previous := backend
+	backend = b
+
+	return func() {
+		backend = previous
+	}
+}
+
+// rest function to send a REST request and decode the JSON response into out
+func rest(ctx context.Context, hostname string, method string, path string, body interface{}, out interface{}) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		payload, err = json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal REST request: %w", err)
+		}
+	}
+
+	output, err := backend.Request(ctx, hostname, method, path, payload)
+	if err != nil {
+		return err
+	}
+	if out == nil {
+		return nil
+	}
+
+	return command_runner.DecodeJSON(method, []string{path}, output, out)
+}
@@ -1,39 +1,66 @@
 package gh_command
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"log"
-	"os/exec"
+	"net/http"
+
+	"github.com/coding-for-fun-org/lazygithub/pkg/git_command"
 )
 
-type Commit struct {
-	Sha     string `json:"sha"`
-	Message string `json:"message"`
-	Author  string `json:"author"`
-}
+// Commit is the commit of the local git log, so that the commits from GitHub can be used the same way
+type Commit = git_command.Commit
+
+// compareCommitsPerPage is the page size of the commits of the compare API
+const compareCommitsPerPage = 100
 
 // GetBranchCommits function to get the commits between two branches from GitHub
-func GetBranchCommits(owner string, repo string, baseBranch string, headBranch string) []Commit {
-	// Run the GitHub CLI command and capture the output
-	cmd := exec.Command(
-		"gh",
-		"api",
-		fmt.Sprintf("repos/%s/%s/compare/%s...%s", owner, repo, baseBranch, headBranch),
-		"--jq",
-		"[.commits[] | {sha: .sha, message: .commit.message, author: .commit.author.name}]",
-	)
-	output, err := cmd.Output()
-	if err != nil {
-		log.Fatalf("Failed to execute gh command: %v", err)
-	}
+func GetBranchCommits(ctx context.Context, hostname string, owner string, repo string, baseBranch string, headBranch string) ([]Commit, error) {
+	commits := make([]Commit, 0)
 
-	// Parse the JSON output into a slice of strings
-	var commits []Commit
-	err = json.Unmarshal(output, &commits)
-	if err != nil {
-		log.Fatalf("Failed to unmarshal JSON: %v", err)
-	}
+	for page := 1; ; page++ {
+		var response struct {
+			Commits []struct {
+				Sha    string `json:"sha"`
+				Commit struct {
+					Message string `json:"message"`
+					Author  struct {
+						Name string `json:"name"`
+					} `json:"author"`
+				} `json:"commit"`
+			} `json:"commits"`
+		}
+		err := rest(
+			ctx,
+			hostname,
+			http.MethodGet,
+			fmt.Sprintf(
+				"repos/%s/%s/compare/%s...%s?per_page=%d&page=%d",
+				owner,
+				repo,
+				baseBranch,
+				headBranch,
+				compareCommitsPerPage,
+				page,
+			),
+			nil,
+			&response,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, commit := range response.Commits {
+			commits = append(commits, Commit{
+				Sha:      commit.Sha,
+				Message:  commit.Commit.Message,
+				Author:   commit.Commit.Author.Name,
+				Trailers: git_command.ParseTrailers(commit.Commit.Message),
+			})
+		}
 
-	return commits
+		if len(response.Commits) < compareCommitsPerPage {
+			return commits, nil
+		}
+	}
 }
@@ -0,0 +1,103 @@
+package gh_command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/coding-for-fun-org/lazygithub/pkg/command_runner"
+)
+
+func TestGetBranchCommits(t *testing.T) {
+	comparePage := func(page int) []string {
+		return []string{
+			"api",
+			fmt.Sprintf("repos/owner/repo/compare/main...feature?per_page=100&page=%d", page),
+			"--hostname",
+			"github.example.com",
+		}
+	}
+	// compareResponse builds a page of the compare API with n commits
+	compareResponse := func(n int, offset int) command_runner.FakeResponse {
+		commits := make([]string, 0, n)
+		for i := 0; i < n; i++ {
+			commits = append(commits, fmt.Sprintf(
+				`{"sha": "%d", "commit": {"message": "commit %d", "author": {"name": "Alice"}}}`,
+				offset+i,
+				offset+i,
+			))
+		}
+		return command_runner.FakeResponse{Stdout: `{"commits": [` + strings.Join(commits, ",") + `]}`}
+	}
+
+	tests := []struct {
+		name      string
+		responses []command_runner.FakeResponse
+		want      []Commit
+		wantCount int
+		wantKind  error
+	}{
+		{
+			name: "base test case",
+			responses: []command_runner.FakeResponse{
+				{
+					Stdout: `{"commits": [
+						{"sha": "abc", "commit": {"message": "feat: first\n\nbody", "author": {"name": "Alice"}}},
+						{"sha": "def", "commit": {"message": "fix: second", "author": {"name": "Bob"}}}
+					]}`,
+				},
+			},
+			want: []Commit{
+				{Sha: "abc", Message: "feat: first\n\nbody", Author: "Alice"},
+				{Sha: "def", Message: "fix: second", Author: "Bob"},
+			},
+			wantCount: 2,
+		},
+		{
+			name:      "more commits than a page",
+			responses: []command_runner.FakeResponse{compareResponse(100, 0), compareResponse(100, 100), compareResponse(50, 200)},
+			wantCount: 250,
+		},
+		{
+			name: "branch not pushed",
+			responses: []command_runner.FakeResponse{
+				{
+					Stderr:   "gh: Not Found (HTTP 404)",
+					ExitCode: 1,
+				},
+			},
+			wantKind: command_runner.ErrNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := command_runner.NewFakeRunner()
+			for i, response := range tt.responses {
+				runner.Add(response, "gh", comparePage(i+1)...)
+			}
+			defer command_runner.SetDefault(runner)()
+
+			got, err := GetBranchCommits(context.Background(), "github.example.com", "owner", "repo", "main", "feature")
+			if tt.wantKind != nil {
+				if !errors.Is(err, tt.wantKind) {
+					t.Errorf("GetBranchCommits() error = %v, want kind %v", err, tt.wantKind)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("GetBranchCommits() error = %v", err)
+				return
+			}
+			if len(got) != tt.wantCount {
+				t.Errorf("GetBranchCommits() returned %d commits, want %d", len(got), tt.wantCount)
+				return
+			}
+			if tt.want != nil && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetBranchCommits() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
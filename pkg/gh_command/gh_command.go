@@ -0,0 +1,54 @@
+package gh_command
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/coding-for-fun-org/lazygithub/pkg/command_runner"
+)
+
+// Actor struct to represent the author of a pull request, issue or workflow run
+type Actor struct {
+	Login string `json:"login"`
+}
+
+// ListOptions struct to represent the options shared by the list commands
+type ListOptions struct {
+	Hostname string
+	// RepoName is "owner/name", or "" for the repository of the current directory
+	RepoName string
+	State    string
+	Limit    int
+}
+
+// args method to convert the options into gh command arguments
+func (o ListOptions) args() []string {
+	args := repoArgs(o.Hostname, o.RepoName)
+	if o.State != "" {
+		args = append(args, "--state", o.State)
+	}
+	if o.Limit > 0 {
+		args = append(args, "--limit", fmt.Sprint(o.Limit))
+	}
+
+	return args
+}
+
+// repoArgs function to get the gh command arguments selecting the repository.
+// gh only takes the host as part of the repository, so without a repository the git remotes decide it.
+func repoArgs(hostname string, repoName string) []string {
+	if repoName == "" {
+		return []string{}
+	}
+	if hostname != "" && strings.Count(repoName, "/") == 1 {
+		repoName = hostname + "/" + repoName
+	}
+
+	return []string{"--repo", repoName}
+}
+
+// runJSON function to run a gh command and parse its JSON output into out
+func runJSON(ctx context.Context, args []string, out interface{}) error {
+	return command_runner.OutputJSON(ctx, "gh", args, nil, out)
+}
@@ -0,0 +1,93 @@
+package gh_command
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/coding-for-fun-org/lazygithub/pkg/command_runner"
+)
+
+// graphqlErrorKinds maps the error types of the GitHub GraphQL API to the kind of failure they mean
+var graphqlErrorKinds = map[string]error{
+	"NOT_FOUND":           command_runner.ErrNotFound,
+	"RATE_LIMITED":        command_runner.ErrRateLimited,
+	"INSUFFICIENT_SCOPES": command_runner.ErrMissingScopes,
+}
+
+// GraphQLError struct to represent the errors returned by the GitHub GraphQL API
+type GraphQLError struct {
+	Errors []struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	}
+	// Stderr of the gh command that made the request
+	Stderr string
+}
+
+// Error method to join the messages of all errors
+func (e *GraphQLError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		messages = append(messages, err.Message)
+	}
+
+	return "GitHub API error: " + strings.Join(messages, "; ")
+}
+
+// Unwrap method to expose the kinds of the errors to errors.Is
+func (e *GraphQLError) Unwrap() []error {
+	kinds := make([]error, 0)
+	for _, err := range e.Errors {
+		if strings.HasPrefix(err.Message, "Could not resolve to a Repository") {
+			kinds = append(kinds, command_runner.ErrRepoNotFound)
+		} else if kind, ok := graphqlErrorKinds[err.Type]; ok {
+			kinds = append(kinds, kind)
+		}
+	}
+
+	return kinds
+}
+
+// pageInfo struct to represent the cursor of a paginated GraphQL connection
+type pageInfo struct {
+	HasNextPage bool   `json:"hasNextPage"`
+	EndCursor   string `json:"endCursor"`
+}
+
+// graphql function to run a GraphQL query through the backend and decode its data into out
+func graphql(ctx context.Context, hostname string, query string, variables map[string]interface{}, out interface{}) error {
+	payload, err := json.Marshal(map[string]interface{}{
+		"query":     query,
+		"variables": variables,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal GraphQL request: %w", err)
+	}
+
+	output, err := backend.Request(ctx, hostname, http.MethodPost, "graphql", payload)
+
+	var response struct {
+		Data   json.RawMessage `json:"data"`
+		Errors json.RawMessage `json:"errors"`
+	}
+	// gh exits with a non-zero code when the response has errors, but the body is still returned
+	if jsonErr := json.Unmarshal(output, &response); jsonErr == nil && len(response.Errors) > 0 {
+		graphqlErr := &GraphQLError{}
+		if jsonErr := json.Unmarshal(response.Errors, &graphqlErr.Errors); jsonErr == nil && len(graphqlErr.Errors) > 0 {
+			var commandErr *command_runner.CommandError
+			if errors.As(err, &commandErr) {
+				graphqlErr.Stderr = commandErr.Stderr
+			}
+			return graphqlErr
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	return command_runner.DecodeJSON(http.MethodPost, []string{"graphql"}, response.Data, out)
+}
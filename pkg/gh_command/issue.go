@@ -0,0 +1,42 @@
+package gh_command
+
+import "context"
+
+// Issue struct to represent an issue on GitHub
+type Issue struct {
+	Number int    `json:"number"`
+	URL    string `json:"url"`
+	Title  string `json:"title"`
+	Body   string `json:"body"`
+	State  string `json:"state"`
+	Author Actor  `json:"author"`
+}
+
+// issueFields are the fields requested from gh when listing or viewing issues
+const issueFields = "number,url,title,state,author"
+
+// ListIssues function to list the issues of a repository
+func ListIssues(ctx context.Context, options ListOptions) ([]Issue, error) {
+	args := append([]string{"issue", "list"}, options.args()...)
+	args = append(args, "--json", issueFields)
+
+	var issues []Issue
+	if err := runJSON(ctx, args, &issues); err != nil {
+		return nil, err
+	}
+
+	return issues, nil
+}
+
+// GetIssue function to get the detail of an issue by number or URL
+func GetIssue(ctx context.Context, hostname string, repoName string, selector string) (Issue, error) {
+	args := append([]string{"issue", "view", selector}, repoArgs(hostname, repoName)...)
+	args = append(args, "--json", issueFields+",body")
+
+	var issue Issue
+	if err := runJSON(ctx, args, &issue); err != nil {
+		return Issue{}, err
+	}
+
+	return issue, nil
+}
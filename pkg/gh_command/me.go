@@ -1,23 +1,18 @@
 package gh_command
 
 import (
-	"log"
-	"os/exec"
-	"strings"
+	"context"
+	"net/http"
 )
 
-func GetMyUserLogin() string {
-	cmd := exec.Command(
-		"gh",
-		"api",
-		"user",
-		"--jq",
-		".login",
-	)
-	output, err := cmd.Output()
-	if err != nil {
-		log.Fatalf("Failed to execute gh command: %v", err)
+// GetMyUserLogin function to get the login of the authenticated user on the host
+func GetMyUserLogin(ctx context.Context, hostname string) (string, error) {
+	var user struct {
+		Login string `json:"login"`
+	}
+	if err := rest(ctx, hostname, http.MethodGet, "user", nil, &user); err != nil {
+		return "", err
 	}
 
-	return strings.TrimSpace(string(output))
+	return user.Login, nil
 }
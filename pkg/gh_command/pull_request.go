@@ -0,0 +1,383 @@
+package gh_command
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// PullRequest struct to represent a pull request on GitHub
+type PullRequest struct {
+	ID          string `json:"id"`
+	Number      int    `json:"number"`
+	URL         string `json:"url"`
+	Title       string `json:"title"`
+	Body        string `json:"body"`
+	State       string `json:"state"`
+	IsDraft     bool   `json:"isDraft"`
+	HeadRefName string `json:"headRefName"`
+	BaseRefName string `json:"baseRefName"`
+	Author      Actor  `json:"author"`
+}
+
+// pullRequestFields are the fields requested from gh when listing or viewing pull requests
+const pullRequestFields = "id,number,url,title,state,isDraft,headRefName,baseRefName,author"
+
+// ListPullRequests function to list the pull requests of a repository
+func ListPullRequests(ctx context.Context, options ListOptions) ([]PullRequest, error) {
+	args := append([]string{"pr", "list"}, options.args()...)
+	args = append(args, "--json", pullRequestFields)
+
+	var pullRequests []PullRequest
+	if err := runJSON(ctx, args, &pullRequests); err != nil {
+		return nil, err
+	}
+
+	return pullRequests, nil
+}
+
+// GetPullRequest function to get the detail of a pull request by number, URL or branch
+func GetPullRequest(ctx context.Context, hostname string, repoName string, selector string) (PullRequest, error) {
+	args := append([]string{"pr", "view", selector}, repoArgs(hostname, repoName)...)
+	args = append(args, "--json", pullRequestFields+",body")
+
+	var pullRequest PullRequest
+	if err := runJSON(ctx, args, &pullRequest); err != nil {
+		return PullRequest{}, err
+	}
+
+	return pullRequest, nil
+}
+
+// CreatePullRequestOptions struct to represent the options for creating a pull request
+type CreatePullRequestOptions struct {
+	Hostname   string
+	RepoID     string
+	BaseBranch string
+	// HeadBranch is "owner:branch" when the branch is in a fork
+	HeadBranch string
+	Title      string
+	Body       string
+	IsDraft    bool
+	// MaintainerCanModify lets the maintainers of the base repository push to the head branch of a fork,
+	// nil keeps the default of GitHub
+	MaintainerCanModify *bool
+}
+
+// CreatePullRequest function to create a pull request on GitHub
+func CreatePullRequest(ctx context.Context, options CreatePullRequestOptions) (PullRequest, error) {
+	query := `mutation CreatePullRequest($input: CreatePullRequestInput!) {
+  createPullRequest(input: $input) {
+    pullRequest { id number url }
+  }
+}`
+	input := map[string]interface{}{
+		"repositoryId": options.RepoID,
+		"baseRefName":  options.BaseBranch,
+		"headRefName":  options.HeadBranch,
+		"title":        options.Title,
+		"body":         options.Body,
+		"draft":        options.IsDraft,
+	}
+	if options.MaintainerCanModify != nil {
+		input["maintainerCanModify"] = *options.MaintainerCanModify
+	}
+	variables := map[string]interface{}{"input": input}
+
+	var data struct {
+		CreatePullRequest struct {
+			PullRequest PullRequest `json:"pullRequest"`
+		} `json:"createPullRequest"`
+	}
+	if err := graphql(ctx, options.Hostname, query, variables, &data); err != nil {
+		return PullRequest{}, err
+	}
+
+	return data.CreatePullRequest.PullRequest, nil
+}
+
+// RequestReviews function to request reviews from the given users and teams on a pull request
+func RequestReviews(ctx context.Context, hostname string, pullRequestID string, userIDs []string, teamIDs []string) error {
+	if len(userIDs) == 0 && len(teamIDs) == 0 {
+		return nil
+	}
+
+	query := `mutation RequestReviews($input: RequestReviewsInput!) {
+  requestReviews(input: $input) {
+    clientMutationId
+  }
+}`
+	variables := map[string]interface{}{
+		"input": map[string]interface{}{
+			"pullRequestId": pullRequestID,
+			"userIds":       userIDs,
+			"teamIds":       teamIDs,
+			"union":         true,
+		},
+	}
+
+	var data struct{}
+	return graphql(ctx, hostname, query, variables, &data)
+}
+
+// UpdatePullRequestOptions struct to represent the changes to make to a pull request, nil leaves a value unchanged
+type UpdatePullRequestOptions struct {
+	Hostname      string
+	PullRequestID string
+	Title         *string
+	Body          *string
+	BaseBranch    *string
+	// LabelIDs and AssigneeIDs replace the current labels and assignees
+	LabelIDs    []string
+	AssigneeIDs []string
+	// MilestoneID is an empty string to remove the milestone
+	MilestoneID         *string
+	MaintainerCanModify *bool
+}
+
+// UpdatePullRequest function to change the title, body, base branch, labels, assignees and milestone of a pull request
+func UpdatePullRequest(ctx context.Context, options UpdatePullRequestOptions) error {
+	input := map[string]interface{}{}
+	if options.Title != nil {
+		input["title"] = *options.Title
+	}
+	if options.Body != nil {
+		input["body"] = *options.Body
+	}
+	if options.BaseBranch != nil {
+		input["baseRefName"] = *options.BaseBranch
+	}
+	if options.LabelIDs != nil {
+		input["labelIds"] = options.LabelIDs
+	}
+	if options.AssigneeIDs != nil {
+		input["assigneeIds"] = options.AssigneeIDs
+	}
+	if options.MilestoneID != nil {
+		if *options.MilestoneID == "" {
+			// An explicit null removes the milestone, leaving the field out would keep it
+			input["milestoneId"] = nil
+		} else {
+			input["milestoneId"] = *options.MilestoneID
+		}
+	}
+	if options.MaintainerCanModify != nil {
+		input["maintainerCanModify"] = *options.MaintainerCanModify
+	}
+	if len(input) == 0 {
+		return nil
+	}
+	input["pullRequestId"] = options.PullRequestID
+
+	query := `mutation UpdatePullRequest($input: UpdatePullRequestInput!) {
+  updatePullRequest(input: $input) {
+    clientMutationId
+  }
+}`
+
+	var data struct{}
+	return graphql(ctx, options.Hostname, query, map[string]interface{}{"input": input}, &data)
+}
+
+// SetPullRequestDraft function to convert a pull request to a draft, or mark it as ready for review
+func SetPullRequestDraft(ctx context.Context, hostname string, pullRequestID string, isDraft bool) error {
+	query := `mutation MarkPullRequestReadyForReview($input: MarkPullRequestReadyForReviewInput!) {
+  markPullRequestReadyForReview(input: $input) {
+    clientMutationId
+  }
+}`
+	if isDraft {
+		query = `mutation ConvertPullRequestToDraft($input: ConvertPullRequestToDraftInput!) {
+  convertPullRequestToDraft(input: $input) {
+    clientMutationId
+  }
+}`
+	}
+	variables := map[string]interface{}{
+		"input": map[string]interface{}{"pullRequestId": pullRequestID},
+	}
+
+	var data struct{}
+	return graphql(ctx, hostname, query, variables, &data)
+}
+
+// RemoveReviewRequests function to withdraw the review requests of users and "org/team" teams from a pull request
+func RemoveReviewRequests(
+	ctx context.Context,
+	hostname string,
+	owner string,
+	repo string,
+	number int,
+	logins []string,
+	teams []string,
+) error {
+	if len(logins) == 0 && len(teams) == 0 {
+		return nil
+	}
+
+	// The REST API only takes the slug of a team, the organization is the owner of the repository
+	teamSlugs := make([]string, 0, len(teams))
+	for _, team := range teams {
+		teamSlugs = append(teamSlugs, team[strings.Index(team, "/")+1:])
+	}
+
+	return rest(
+		ctx,
+		hostname,
+		http.MethodDelete,
+		fmt.Sprintf("repos/%s/%s/pulls/%d/requested_reviewers", owner, repo, number),
+		map[string]interface{}{"reviewers": logins, "team_reviewers": teamSlugs},
+		nil,
+	)
+}
+
+// PullRequestDetail struct to represent an open pull request with the values that can be edited on it
+type PullRequestDetail struct {
+	PullRequest
+	Labels []RepoLabel
+	// Assignees are logins
+	Assignees []string
+	// Milestone is the title of the milestone, empty when there is none
+	Milestone string
+	// RequestedReviewers are the logins of the users whose review is still requested
+	RequestedReviewers []string
+	// RequestedTeams are the "org/team" slugs of the teams whose review is still requested
+	RequestedTeams      []string
+	MaintainerCanModify bool
+}
+
+// openPullRequestQuery is the GraphQL query of the open pull request of a head branch
+const openPullRequestQuery = `query OpenPullRequest($owner: String!, $name: String!, $head: String!) {
+  repository(owner: $owner, name: $name) {
+    pullRequests(headRefName: $head, states: OPEN, first: 100) {
+      nodes {
+        id number url title body state isDraft headRefName baseRefName maintainerCanModify
+        author { login }
+        headRepositoryOwner { login }
+        labels(first: 100) { nodes { id name } }
+        assignees(first: 100) { nodes { login } }
+        milestone { title }
+        reviewRequests(first: 100) {
+          nodes {
+            requestedReviewer {
+              __typename
+              ... on User { login }
+              ... on Team { slug organization { login } }
+            }
+          }
+        }
+      }
+    }
+  }
+}`
+
+// FindOpenPullRequest function to find the open pull request whose head is the given branch, nil when there is none.
+// headOwner is the owner of the repository of the head branch, the one of a fork or owner itself,
+// since branches of forks with the same name are listed too.
+func FindOpenPullRequest(
+	ctx context.Context,
+	hostname string,
+	owner string,
+	repo string,
+	headOwner string,
+	headBranch string,
+) (*PullRequestDetail, error) {
+	var data struct {
+		Repository struct {
+			PullRequests struct {
+				Nodes []struct {
+					PullRequest
+					MaintainerCanModify bool `json:"maintainerCanModify"`
+					// HeadRepositoryOwner is null when the fork was deleted
+					HeadRepositoryOwner *Actor `json:"headRepositoryOwner"`
+					Labels              struct {
+						Nodes []RepoLabel `json:"nodes"`
+					} `json:"labels"`
+					Assignees struct {
+						Nodes []Actor `json:"nodes"`
+					} `json:"assignees"`
+					Milestone *struct {
+						Title string `json:"title"`
+					} `json:"milestone"`
+					ReviewRequests struct {
+						Nodes []struct {
+							RequestedReviewer struct {
+								Type         string `json:"__typename"`
+								Login        string `json:"login"`
+								Slug         string `json:"slug"`
+								Organization Actor  `json:"organization"`
+							} `json:"requestedReviewer"`
+						} `json:"nodes"`
+					} `json:"reviewRequests"`
+				} `json:"nodes"`
+			} `json:"pullRequests"`
+		} `json:"repository"`
+	}
+	err := graphql(
+		ctx,
+		hostname,
+		openPullRequestQuery,
+		map[string]interface{}{"owner": owner, "name": repo, "head": headBranch},
+		&data,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	found := -1
+	for i, node := range data.Repository.PullRequests.Nodes {
+		if node.HeadRepositoryOwner != nil && strings.EqualFold(node.HeadRepositoryOwner.Login, headOwner) {
+			found = i
+			break
+		}
+	}
+	if found < 0 {
+		return nil, nil
+	}
+
+	node := data.Repository.PullRequests.Nodes[found]
+	detail := &PullRequestDetail{
+		PullRequest:         node.PullRequest,
+		Labels:              node.Labels.Nodes,
+		Assignees:           make([]string, 0, len(node.Assignees.Nodes)),
+		RequestedReviewers:  make([]string, 0),
+		RequestedTeams:      make([]string, 0),
+		MaintainerCanModify: node.MaintainerCanModify,
+	}
+	for _, assignee := range node.Assignees.Nodes {
+		detail.Assignees = append(detail.Assignees, assignee.Login)
+	}
+	if node.Milestone != nil {
+		detail.Milestone = node.Milestone.Title
+	}
+	for _, request := range node.ReviewRequests.Nodes {
+		reviewer := request.RequestedReviewer
+		switch reviewer.Type {
+		case "User":
+			detail.RequestedReviewers = append(detail.RequestedReviewers, reviewer.Login)
+		case "Team":
+			detail.RequestedTeams = append(detail.RequestedTeams, reviewer.Organization.Login+"/"+reviewer.Slug)
+		}
+	}
+
+	return detail, nil
+}
+
+// AddToProject function to add a pull request or an issue to a Projects (v2) board
+func AddToProject(ctx context.Context, hostname string, projectID string, contentID string) error {
+	query := `mutation AddProjectV2ItemById($input: AddProjectV2ItemByIdInput!) {
+  addProjectV2ItemById(input: $input) {
+    item { id }
+  }
+}`
+	variables := map[string]interface{}{
+		"input": map[string]interface{}{
+			"projectId": projectID,
+			"contentId": contentID,
+		},
+	}
+
+	var data struct{}
+	return graphql(ctx, hostname, query, variables, &data)
+}
@@ -1,12 +1,20 @@
 package gh_command
 
 import (
-	"encoding/json"
-	"log"
-	"os/exec"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/coding-for-fun-org/lazygithub/pkg/command_runner"
+	"github.com/coding-for-fun-org/lazygithub/pkg/git_command"
 )
 
 type Repo struct {
+	// Hostname is the GitHub host, the git remotes decide it when empty
+	Hostname string
 	RepoName string
 }
 
@@ -14,6 +22,8 @@ type Repo struct {
 type RepoOwner struct {
 	ID    string `json:"id"`
 	Login string `json:"login"`
+	// Type is either "Organization" or "User"
+	Type string `json:"__typename"`
 }
 
 // RepoAssignableUser struct to represent an assignable user
@@ -28,9 +38,38 @@ type RepoDefaultBranchRef struct {
 	Name string `json:"name"`
 }
 
+// RepoTeam struct to represent a team with access to the repository, one that can be requested for review
+type RepoTeam struct {
+	ID   string `json:"id"`
+	Slug string `json:"slug"`
+	Name string `json:"name"`
+}
+
+// RepoLabel struct to represent a label of the repository
+type RepoLabel struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// RepoMilestone struct to represent an open milestone of the repository
+type RepoMilestone struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
+
+// RepoProject struct to represent a Projects (v2) board linked to the repository
+type RepoProject struct {
+	ID     string `json:"id"`
+	Title  string `json:"title"`
+	Closed bool   `json:"closed"`
+}
+
 // GetRepoOptions struct to represent the options for getting a repository
 type GetRepoOptions struct {
-	Json string
+	// Teams also loads the teams with access to the repository, left empty when the token lacks the read:org scope
+	Teams bool
+	// Metadata also loads the labels, the open milestones and the open projects of the repository
+	Metadata bool
 }
 
 // GetRepoResponse struct to represent the response of getting a repository
@@ -40,34 +79,309 @@ type GetRepoResponse struct {
 	ID               string               `json:"id"`
 	Owner            RepoOwner            `json:"owner"`
 	Name             string               `json:"name"`
+	// Teams is only loaded when asked for, and is empty for a repository owned by a user
+	Teams []RepoTeam `json:"teams"`
+	// Labels, Milestones and Projects are only loaded when asked for.
+	// Projects is empty when the token lacks the read:project scope.
+	Labels     []RepoLabel     `json:"labels"`
+	Milestones []RepoMilestone `json:"milestones"`
+	Projects   []RepoProject   `json:"projects"`
+	// Host is the GitHub host the repository was resolved on
+	Host string `json:"-"`
+}
+
+// RepoRef struct to represent where a repository lives
+type RepoRef struct {
+	Host  string
+	Owner string
+	Name  string
 }
 
+// remotePriority orders the git remotes the way gh picks the base repository
+var remotePriority = map[string]int{"upstream": 0, "github": 1, "origin": 2}
+
+// ResolveRepo function to find the repository from "owner/name", "host/owner/name",
+// or the git remotes of the current directory when repoName is empty.
+// A non-empty hostname is used for "owner/name" and restricts the git remotes to that host.
+func ResolveRepo(ctx context.Context, repoName string, hostname string) (RepoRef, error) {
+	if repoName != "" {
+		parts := strings.Split(repoName, "/")
+		switch {
+		case len(parts) == 2 && parts[0] != "" && parts[1] != "":
+			if hostname == "" {
+				hostname = DefaultHostname()
+			}
+			return RepoRef{Host: hostname, Owner: parts[0], Name: parts[1]}, nil
+		case len(parts) == 3 && parts[0] != "" && parts[1] != "" && parts[2] != "":
+			return RepoRef{Host: parts[0], Owner: parts[1], Name: parts[2]}, nil
+		default:
+			return RepoRef{}, fmt.Errorf("expected the \"[HOST/]OWNER/NAME\" format but got %q", repoName)
+		}
+	}
+
+	remotes, err := git_command.ListRemotes(ctx)
+	if err != nil {
+		return RepoRef{}, err
+	}
+	if hostname != "" {
+		onHost := make([]git_command.Remote, 0, len(remotes))
+		for _, remote := range remotes {
+			if strings.EqualFold(remote.Host, hostname) {
+				onHost = append(onHost, remote)
+			}
+		}
+		if len(onHost) == 0 {
+			return RepoRef{}, fmt.Errorf("no git remotes found on %s: %w", hostname, command_runner.ErrRepoNotFound)
+		}
+		remotes = onHost
+	}
+	if len(remotes) == 0 {
+		return RepoRef{}, fmt.Errorf("no git remotes found: %w", command_runner.ErrRepoNotFound)
+	}
+
+	sort.SliceStable(remotes, func(i, j int) bool {
+		pi, ok := remotePriority[remotes[i].Name]
+		if !ok {
+			pi = len(remotePriority)
+		}
+		pj, ok := remotePriority[remotes[j].Name]
+		if !ok {
+			pj = len(remotePriority)
+		}
+		return pi < pj
+	})
+
+	return RepoRef{Host: remotes[0].Host, Owner: remotes[0].Owner, Name: remotes[0].Repo}, nil
+}
+
+// repositoryQuery is the GraphQL query of the repository detail, assignable users are paginated
+const repositoryQuery = `query Repository($owner: String!, $name: String!, $after: String) {
+  repository(owner: $owner, name: $name) {
+    id
+    name
+    owner { __typename id login }
+    defaultBranchRef { name }
+    assignableUsers(first: 100, after: $after) {
+      nodes { id login name }
+      pageInfo { hasNextPage endCursor }
+    }
+  }
+}`
+
 // GetRepo function to get the detail of a repository
 func (r *Repo) Get(
+	ctx context.Context,
 	options GetRepoOptions,
-) GetRepoResponse {
-	args := []string{
-		"repo",
-		"view",
-		r.RepoName,
+) (GetRepoResponse, error) {
+	ref, err := ResolveRepo(ctx, r.RepoName, r.Hostname)
+	if err != nil {
+		return GetRepoResponse{}, err
 	}
-	if options.Json == "" {
-		args = append(args, "--json")
-		args = append(args, "assignableUsers,defaultBranchRef,owner,name,id")
+
+	repo := GetRepoResponse{Host: ref.Host}
+	var after interface{}
+	for {
+		var data struct {
+			Repository struct {
+				ID               string               `json:"id"`
+				Name             string               `json:"name"`
+				Owner            RepoOwner            `json:"owner"`
+				DefaultBranchRef RepoDefaultBranchRef `json:"defaultBranchRef"`
+				AssignableUsers  struct {
+					Nodes    []RepoAssignableUser `json:"nodes"`
+					PageInfo pageInfo             `json:"pageInfo"`
+				} `json:"assignableUsers"`
+			} `json:"repository"`
+		}
+		err := graphql(
+			ctx,
+			ref.Host,
+			repositoryQuery,
+			map[string]interface{}{"owner": ref.Owner, "name": ref.Name, "after": after},
+			&data,
+		)
+		if err != nil {
+			return GetRepoResponse{}, err
+		}
+
+		repo.ID = data.Repository.ID
+		repo.Name = data.Repository.Name
+		repo.Owner = data.Repository.Owner
+		repo.DefaultBranchRef = data.Repository.DefaultBranchRef
+		repo.AssignableUsers = append(repo.AssignableUsers, data.Repository.AssignableUsers.Nodes...)
+
+		if !data.Repository.AssignableUsers.PageInfo.HasNextPage {
+			break
+		}
+		after = data.Repository.AssignableUsers.PageInfo.EndCursor
 	}
-	// Run the GitHub CLI command and capture the output
-	cmd := exec.Command("gh", args...)
-	output, err := cmd.Output()
-	if err != nil {
-		log.Fatalf("Failed to execute gh command: %v", err)
+
+	if options.Teams && repo.Owner.Type == "Organization" {
+		teams, err := listRepositoryTeams(ctx, ref)
+		// The teams may need the read:org scope, the users can still be requested for review without them
+		if err != nil && !errors.Is(err, command_runner.ErrMissingScopes) {
+			return GetRepoResponse{}, err
+		}
+		repo.Teams = teams
 	}
 
-	// Parse the JSON output into a slice of repo detail structs
-	var repo GetRepoResponse
-	err = json.Unmarshal(output, &repo)
-	if err != nil {
-		log.Fatalf("Failed to unmarshal JSON: %v", err)
+	if options.Metadata {
+		labels, milestones, err := listRepositoryLabelsAndMilestones(ctx, ref)
+		if err != nil {
+			return GetRepoResponse{}, err
+		}
+		repo.Labels = labels
+		repo.Milestones = milestones
+
+		projects, err := listRepositoryProjects(ctx, ref)
+		// Projects need a scope gh does not ask for by default, the rest of the form works without them
+		if err != nil && !errors.Is(err, command_runner.ErrMissingScopes) {
+			return GetRepoResponse{}, err
+		}
+		repo.Projects = projects
+	}
+
+	return repo, nil
+}
+
+// labelsAndMilestonesQuery is the GraphQL query of the labels and the open milestones of a repository.
+// Both connections are paginated, a connection is left out once its last page was read.
+const labelsAndMilestonesQuery = `query RepositoryLabelsAndMilestones(
+  $owner: String!,
+  $name: String!,
+  $labels: Boolean!,
+  $labelsAfter: String,
+  $milestones: Boolean!,
+  $milestonesAfter: String
+) {
+  repository(owner: $owner, name: $name) {
+    labels(first: 100, after: $labelsAfter, orderBy: {field: NAME, direction: ASC}) @include(if: $labels) {
+      nodes { id name }
+      pageInfo { hasNextPage endCursor }
+    }
+    milestones(first: 100, after: $milestonesAfter, states: OPEN, orderBy: {field: DUE_DATE, direction: ASC}) @include(if: $milestones) {
+      nodes { id title }
+      pageInfo { hasNextPage endCursor }
+    }
+  }
+}`
+
+// listRepositoryLabelsAndMilestones function to list every label and open milestone of a repository
+func listRepositoryLabelsAndMilestones(ctx context.Context, ref RepoRef) ([]RepoLabel, []RepoMilestone, error) {
+	labels := make([]RepoLabel, 0)
+	milestones := make([]RepoMilestone, 0)
+	variables := map[string]interface{}{
+		"owner":           ref.Owner,
+		"name":            ref.Name,
+		"labels":          true,
+		"labelsAfter":     nil,
+		"milestones":      true,
+		"milestonesAfter": nil,
 	}
+	for variables["labels"] == true || variables["milestones"] == true {
+		var data struct {
+			Repository struct {
+				Labels struct {
+					Nodes    []RepoLabel `json:"nodes"`
+					PageInfo pageInfo    `json:"pageInfo"`
+				} `json:"labels"`
+				Milestones struct {
+					Nodes    []RepoMilestone `json:"nodes"`
+					PageInfo pageInfo        `json:"pageInfo"`
+				} `json:"milestones"`
+			} `json:"repository"`
+		}
+		if err := graphql(ctx, ref.Host, labelsAndMilestonesQuery, variables, &data); err != nil {
+			return nil, nil, err
+		}
 
-	return repo
+		labels = append(labels, data.Repository.Labels.Nodes...)
+		milestones = append(milestones, data.Repository.Milestones.Nodes...)
+
+		variables["labels"] = data.Repository.Labels.PageInfo.HasNextPage
+		variables["labelsAfter"] = data.Repository.Labels.PageInfo.EndCursor
+		variables["milestones"] = data.Repository.Milestones.PageInfo.HasNextPage
+		variables["milestonesAfter"] = data.Repository.Milestones.PageInfo.EndCursor
+	}
+
+	return labels, milestones, nil
+}
+
+// repositoryProjectsQuery is the GraphQL query of the Projects (v2) boards linked to a repository, the projects are paginated
+const repositoryProjectsQuery = `query RepositoryProjects($owner: String!, $name: String!, $after: String) {
+  repository(owner: $owner, name: $name) {
+    projectsV2(first: 100, after: $after, orderBy: {field: TITLE, direction: ASC}) {
+      nodes { id title closed }
+      pageInfo { hasNextPage endCursor }
+    }
+  }
+}`
+
+// listRepositoryProjects function to list the open Projects (v2) boards linked to a repository
+func listRepositoryProjects(ctx context.Context, ref RepoRef) ([]RepoProject, error) {
+	projects := make([]RepoProject, 0)
+	var after interface{}
+	for {
+		var data struct {
+			Repository struct {
+				ProjectsV2 struct {
+					Nodes    []RepoProject `json:"nodes"`
+					PageInfo pageInfo      `json:"pageInfo"`
+				} `json:"projectsV2"`
+			} `json:"repository"`
+		}
+		err := graphql(
+			ctx,
+			ref.Host,
+			repositoryProjectsQuery,
+			map[string]interface{}{"owner": ref.Owner, "name": ref.Name, "after": after},
+			&data,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, project := range data.Repository.ProjectsV2.Nodes {
+			if !project.Closed {
+				projects = append(projects, project)
+			}
+		}
+		if !data.Repository.ProjectsV2.PageInfo.HasNextPage {
+			return projects, nil
+		}
+		after = data.Repository.ProjectsV2.PageInfo.EndCursor
+	}
+}
+
+// repositoryTeamsPerPage is the page size of the teams of a repository
+const repositoryTeamsPerPage = 100
+
+// listRepositoryTeams function to list the teams with access to a repository, the ones that can be requested for review
+func listRepositoryTeams(ctx context.Context, ref RepoRef) ([]RepoTeam, error) {
+	teams := make([]RepoTeam, 0)
+	for page := 1; ; page++ {
+		var response []struct {
+			NodeID string `json:"node_id"`
+			Slug   string `json:"slug"`
+			Name   string `json:"name"`
+		}
+		err := rest(
+			ctx,
+			ref.Host,
+			http.MethodGet,
+			fmt.Sprintf("repos/%s/%s/teams?per_page=%d&page=%d", ref.Owner, ref.Name, repositoryTeamsPerPage, page),
+			nil,
+			&response,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, team := range response {
+			teams = append(teams, RepoTeam{ID: team.NodeID, Slug: team.Slug, Name: team.Name})
+		}
+		if len(response) < repositoryTeamsPerPage {
+			return teams, nil
+		}
+	}
 }
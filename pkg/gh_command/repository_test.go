@@ -0,0 +1,388 @@
+package gh_command
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/coding-for-fun-org/lazygithub/pkg/command_runner"
+)
+
+func TestRepo_Get(t *testing.T) {
+	tests := []struct {
+		name      string
+		hostname  string
+		repoName  string
+		options   GetRepoOptions
+		responses []command_runner.FakeResponse
+		// teams is the response of the REST API listing the teams of the repository
+		teams    command_runner.FakeResponse
+		remotes  string
+		wantHost string
+		want     GetRepoResponse
+		wantKind error
+	}{
+		{
+			name:     "assignable users of every page",
+			repoName: "owner/repo",
+			responses: []command_runner.FakeResponse{
+				{Stdout: `{"data": {"repository": {
+					"id": "R_1",
+					"name": "repo",
+					"owner": {"id": "O_1", "login": "owner"},
+					"defaultBranchRef": {"name": "main"},
+					"assignableUsers": {
+						"nodes": [{"id": "U_1", "login": "alice", "name": "Alice"}],
+						"pageInfo": {"hasNextPage": true, "endCursor": "cursor"}
+					}
+				}}}`},
+				{Stdout: `{"data": {"repository": {
+					"id": "R_1",
+					"name": "repo",
+					"owner": {"id": "O_1", "login": "owner"},
+					"defaultBranchRef": {"name": "main"},
+					"assignableUsers": {
+						"nodes": [{"id": "U_2", "login": "bob", "name": null}],
+						"pageInfo": {"hasNextPage": false, "endCursor": "cursor2"}
+					}
+				}}}`},
+			},
+			wantHost: "github.com",
+			want: GetRepoResponse{
+				AssignableUsers: []RepoAssignableUser{
+					{ID: "U_1", Login: "alice", Name: "Alice"},
+					{ID: "U_2", Login: "bob", Name: ""},
+				},
+				DefaultBranchRef: RepoDefaultBranchRef{Name: "main"},
+				ID:               "R_1",
+				Name:             "repo",
+				Owner:            RepoOwner{ID: "O_1", Login: "owner"},
+				Host:             "github.com",
+			},
+		},
+		{
+			name:     "teams with access to the repository of an organization",
+			repoName: "owner/repo",
+			options:  GetRepoOptions{Teams: true},
+			responses: []command_runner.FakeResponse{
+				{Stdout: `{"data": {"repository": {
+					"id": "R_1",
+					"name": "repo",
+					"owner": {"__typename": "Organization", "id": "O_1", "login": "owner"},
+					"defaultBranchRef": {"name": "main"},
+					"assignableUsers": {"nodes": [], "pageInfo": {"hasNextPage": false, "endCursor": null}}
+				}}}`},
+			},
+			teams: command_runner.FakeResponse{Stdout: `[
+				{"id": 1, "node_id": "T_1", "slug": "backend", "name": "Backend"},
+				{"id": 2, "node_id": "T_2", "slug": "docs", "name": "Docs"}
+			]`},
+			wantHost: "github.com",
+			want: GetRepoResponse{
+				DefaultBranchRef: RepoDefaultBranchRef{Name: "main"},
+				ID:               "R_1",
+				Name:             "repo",
+				Owner:            RepoOwner{ID: "O_1", Login: "owner", Type: "Organization"},
+				Teams: []RepoTeam{
+					{ID: "T_1", Slug: "backend", Name: "Backend"},
+					{ID: "T_2", Slug: "docs", Name: "Docs"},
+				},
+				Host: "github.com",
+			},
+		},
+		{
+			name:     "no teams without the read:org scope",
+			repoName: "owner/repo",
+			options:  GetRepoOptions{Teams: true},
+			responses: []command_runner.FakeResponse{
+				{Stdout: `{"data": {"repository": {
+					"id": "R_1",
+					"name": "repo",
+					"owner": {"__typename": "Organization", "id": "O_1", "login": "owner"},
+					"defaultBranchRef": {"name": "main"},
+					"assignableUsers": {"nodes": [], "pageInfo": {"hasNextPage": false, "endCursor": null}}
+				}}}`},
+			},
+			teams: command_runner.FakeResponse{
+				Stderr:   `gh: This API operation needs the "read:org" scope. To request it, run:  gh auth refresh -h github.com -s read:org`,
+				ExitCode: 1,
+			},
+			wantHost: "github.com",
+			want: GetRepoResponse{
+				DefaultBranchRef: RepoDefaultBranchRef{Name: "main"},
+				ID:               "R_1",
+				Name:             "repo",
+				Owner:            RepoOwner{ID: "O_1", Login: "owner", Type: "Organization"},
+				Host:             "github.com",
+			},
+		},
+		{
+			name:     "no teams for a repository owned by a user",
+			repoName: "owner/repo",
+			options:  GetRepoOptions{Teams: true},
+			responses: []command_runner.FakeResponse{
+				{Stdout: `{"data": {"repository": {
+					"id": "R_1",
+					"name": "repo",
+					"owner": {"__typename": "User", "id": "O_1", "login": "owner"},
+					"defaultBranchRef": {"name": "main"},
+					"assignableUsers": {"nodes": [], "pageInfo": {"hasNextPage": false, "endCursor": null}}
+				}}}`},
+			},
+			wantHost: "github.com",
+			want: GetRepoResponse{
+				DefaultBranchRef: RepoDefaultBranchRef{Name: "main"},
+				ID:               "R_1",
+				Name:             "repo",
+				Owner:            RepoOwner{ID: "O_1", Login: "owner", Type: "User"},
+				Host:             "github.com",
+			},
+		},
+		{
+			name:     "labels, milestones and projects of every page",
+			repoName: "owner/repo",
+			options:  GetRepoOptions{Metadata: true},
+			responses: []command_runner.FakeResponse{
+				{Stdout: `{"data": {"repository": {
+					"id": "R_1",
+					"name": "repo",
+					"owner": {"__typename": "User", "id": "O_1", "login": "owner"},
+					"defaultBranchRef": {"name": "main"},
+					"assignableUsers": {"nodes": [], "pageInfo": {"hasNextPage": false, "endCursor": null}}
+				}}}`},
+				{Stdout: `{"data": {"repository": {
+					"labels": {
+						"nodes": [{"id": "L_1", "name": "bug"}],
+						"pageInfo": {"hasNextPage": true, "endCursor": "cursor"}
+					},
+					"milestones": {
+						"nodes": [{"id": "M_1", "title": "v1.0"}],
+						"pageInfo": {"hasNextPage": false, "endCursor": "cursor"}
+					}
+				}}}`},
+				{Stdout: `{"data": {"repository": {
+					"labels": {
+						"nodes": [{"id": "L_2", "name": "feature"}],
+						"pageInfo": {"hasNextPage": false, "endCursor": "cursor2"}
+					}
+				}}}`},
+				{Stdout: `{"data": {"repository": {"projectsV2": {
+					"nodes": [{"id": "P_1", "title": "Roadmap", "closed": false}, {"id": "P_2", "title": "Old", "closed": true}],
+					"pageInfo": {"hasNextPage": false, "endCursor": null}
+				}}}}`},
+			},
+			wantHost: "github.com",
+			want: GetRepoResponse{
+				DefaultBranchRef: RepoDefaultBranchRef{Name: "main"},
+				ID:               "R_1",
+				Name:             "repo",
+				Owner:            RepoOwner{ID: "O_1", Login: "owner", Type: "User"},
+				Labels:           []RepoLabel{{ID: "L_1", Name: "bug"}, {ID: "L_2", Name: "feature"}},
+				Milestones:       []RepoMilestone{{ID: "M_1", Title: "v1.0"}},
+				Projects:         []RepoProject{{ID: "P_1", Title: "Roadmap"}},
+				Host:             "github.com",
+			},
+		},
+		{
+			name:     "no projects without the read:project scope",
+			repoName: "owner/repo",
+			options:  GetRepoOptions{Metadata: true},
+			responses: []command_runner.FakeResponse{
+				{Stdout: `{"data": {"repository": {
+					"id": "R_1",
+					"name": "repo",
+					"owner": {"__typename": "User", "id": "O_1", "login": "owner"},
+					"defaultBranchRef": {"name": "main"},
+					"assignableUsers": {"nodes": [], "pageInfo": {"hasNextPage": false, "endCursor": null}}
+				}}}`},
+				{Stdout: `{"data": {"repository": {
+					"labels": {"nodes": [], "pageInfo": {"hasNextPage": false, "endCursor": null}},
+					"milestones": {"nodes": [], "pageInfo": {"hasNextPage": false, "endCursor": null}}
+				}}}`},
+				{
+					Stdout:   `{"errors": [{"type": "INSUFFICIENT_SCOPES", "message": "Your token has not been granted the required scopes to execute this query."}]}`,
+					Stderr:   "gh: Your token has not been granted the required scopes to execute this query.",
+					ExitCode: 1,
+				},
+			},
+			wantHost: "github.com",
+			want: GetRepoResponse{
+				DefaultBranchRef: RepoDefaultBranchRef{Name: "main"},
+				ID:               "R_1",
+				Name:             "repo",
+				Owner:            RepoOwner{ID: "O_1", Login: "owner", Type: "User"},
+				Labels:           []RepoLabel{},
+				Milestones:       []RepoMilestone{},
+				Host:             "github.com",
+			},
+		},
+		{
+			name:     "repository from the git remotes",
+			repoName: "",
+			remotes: "origin\tgit@github.com:fork/repo.git (fetch)\norigin\tgit@github.com:fork/repo.git (push)\n" +
+				"upstream\thttps://github.com/owner/repo.git (fetch)\nupstream\thttps://github.com/owner/repo.git (push)\n",
+			responses: []command_runner.FakeResponse{
+				{Stdout: `{"data": {"repository": {
+					"id": "R_1",
+					"name": "repo",
+					"owner": {"id": "O_1", "login": "owner"},
+					"defaultBranchRef": {"name": "main"},
+					"assignableUsers": {"nodes": [], "pageInfo": {"hasNextPage": false, "endCursor": null}}
+				}}}`},
+			},
+			wantHost: "github.com",
+			want: GetRepoResponse{
+				AssignableUsers:  nil,
+				DefaultBranchRef: RepoDefaultBranchRef{Name: "main"},
+				ID:               "R_1",
+				Name:             "repo",
+				Owner:            RepoOwner{ID: "O_1", Login: "owner"},
+				Host:             "github.com",
+			},
+		},
+		{
+			name:     "enterprise server repository from the git remotes",
+			repoName: "",
+			remotes:  "origin\tgit@github.example.com:owner/repo.git (fetch)\norigin\tgit@github.example.com:owner/repo.git (push)\n",
+			responses: []command_runner.FakeResponse{
+				{Stdout: `{"data": {"repository": {
+					"id": "R_1",
+					"name": "repo",
+					"owner": {"id": "O_1", "login": "owner"},
+					"defaultBranchRef": {"name": "main"},
+					"assignableUsers": {"nodes": [], "pageInfo": {"hasNextPage": false, "endCursor": null}}
+				}}}`},
+			},
+			wantHost: "github.example.com",
+			want: GetRepoResponse{
+				DefaultBranchRef: RepoDefaultBranchRef{Name: "main"},
+				ID:               "R_1",
+				Name:             "repo",
+				Owner:            RepoOwner{ID: "O_1", Login: "owner"},
+				Host:             "github.example.com",
+			},
+		},
+		{
+			name:     "enterprise server host given for owner and name",
+			hostname: "github.example.com",
+			repoName: "owner/repo",
+			responses: []command_runner.FakeResponse{
+				{Stdout: `{"data": {"repository": {
+					"id": "R_1",
+					"name": "repo",
+					"owner": {"id": "O_1", "login": "owner"},
+					"defaultBranchRef": {"name": "main"},
+					"assignableUsers": {"nodes": [], "pageInfo": {"hasNextPage": false, "endCursor": null}}
+				}}}`},
+			},
+			wantHost: "github.example.com",
+			want: GetRepoResponse{
+				DefaultBranchRef: RepoDefaultBranchRef{Name: "main"},
+				ID:               "R_1",
+				Name:             "repo",
+				Owner:            RepoOwner{ID: "O_1", Login: "owner"},
+				Host:             "github.example.com",
+			},
+		},
+		{
+			name:     "repository not found",
+			repoName: "owner/repo",
+			responses: []command_runner.FakeResponse{
+				{
+					Stdout:   `{"data": {"repository": null}, "errors": [{"type": "NOT_FOUND", "message": "Could not resolve to a Repository with the name 'owner/repo'."}]}`,
+					Stderr:   "gh: Could not resolve to a Repository with the name 'owner/repo'.",
+					ExitCode: 1,
+				},
+			},
+			wantHost: "github.com",
+			wantKind: command_runner.ErrRepoNotFound,
+		},
+		{
+			name:      "invalid JSON",
+			repoName:  "owner/repo",
+			wantHost:  "github.com",
+			responses: []command_runner.FakeResponse{{Stdout: `{"data": {"repository": []}}`}},
+			wantKind:  command_runner.ErrJSONDecode,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("GH_HOST", "")
+			runner := command_runner.NewFakeRunner().
+				Add(command_runner.FakeResponse{Stdout: tt.remotes}, "git", "remote", "-v")
+			for _, response := range tt.responses {
+				runner.Add(response, "gh", "api", "graphql", "--hostname", tt.wantHost, "--input", "-")
+			}
+			runner.Add(tt.teams, "gh", "api", "repos/owner/repo/teams?per_page=100&page=1", "--hostname", tt.wantHost)
+			defer command_runner.SetDefault(runner)()
+
+			r := &Repo{Hostname: tt.hostname, RepoName: tt.repoName}
+			got, err := r.Get(context.Background(), tt.options)
+			if tt.wantKind != nil {
+				if !errors.Is(err, tt.wantKind) {
+					t.Errorf("Repo.Get() error = %v, want kind %v", err, tt.wantKind)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Repo.Get() error = %v", err)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Repo.Get() = %v, want %v", got, tt.want)
+			}
+
+			// The upstream remote is preferred over origin like gh does
+			for _, call := range runner.Calls() {
+				if call.Name == "gh" && strings.Contains(call.Stdin, "query Repository") &&
+					!strings.Contains(call.Stdin, `"owner":"owner"`) {
+					t.Errorf("Repo.Get() requested %s, want the owner/repo repository", call.Stdin)
+				}
+			}
+		})
+	}
+}
+
+func TestResolveRepo(t *testing.T) {
+	remotes := "origin\tgit@github.com:fork/repo.git (fetch)\n" +
+		"upstream\thttps://github.com/owner/repo.git (fetch)\n" +
+		"work\tgit@github.example.com:team/repo.git (fetch)\n"
+
+	tests := []struct {
+		name     string
+		repoName string
+		hostname string
+		ghHost   string
+		want     RepoRef
+		wantErr  bool
+	}{
+		{name: "owner and name", repoName: "owner/repo", want: RepoRef{Host: "github.com", Owner: "owner", Name: "repo"}},
+		{name: "owner and name on the given host", repoName: "owner/repo", hostname: "github.example.com", want: RepoRef{Host: "github.example.com", Owner: "owner", Name: "repo"}},
+		{name: "owner and name on GH_HOST", repoName: "owner/repo", ghHost: "github.example.com", want: RepoRef{Host: "github.example.com", Owner: "owner", Name: "repo"}},
+		{name: "host, owner and name", repoName: "github.example.com/owner/repo", want: RepoRef{Host: "github.example.com", Owner: "owner", Name: "repo"}},
+		{name: "missing name", repoName: "owner/", wantErr: true},
+		{name: "too many parts", repoName: "a/b/c/d", wantErr: true},
+		{name: "git remotes", want: RepoRef{Host: "github.com", Owner: "owner", Name: "repo"}},
+		{name: "git remotes on the given host", hostname: "github.example.com", want: RepoRef{Host: "github.example.com", Owner: "team", Name: "repo"}},
+		{name: "no git remote on the given host", hostname: "other.example.com", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("GH_HOST", tt.ghHost)
+			runner := command_runner.NewFakeRunner().
+				Add(command_runner.FakeResponse{Stdout: remotes}, "git", "remote", "-v")
+			defer command_runner.SetDefault(runner)()
+
+			got, err := ResolveRepo(context.Background(), tt.repoName, tt.hostname)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ResolveRepo() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ResolveRepo() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package gh_command
+
+import (
+	"context"
+	"fmt"
+)
+
+// WorkflowJob struct to represent a job of a workflow run
+type WorkflowJob struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	Conclusion string `json:"conclusion"`
+}
+
+// WorkflowRun struct to represent a GitHub Actions workflow run
+type WorkflowRun struct {
+	DatabaseID   int64         `json:"databaseId"`
+	URL          string        `json:"url"`
+	DisplayTitle string        `json:"displayTitle"`
+	WorkflowName string        `json:"workflowName"`
+	HeadBranch   string        `json:"headBranch"`
+	Status       string        `json:"status"`
+	Conclusion   string        `json:"conclusion"`
+	Jobs         []WorkflowJob `json:"jobs"`
+}
+
+// workflowRunFields are the fields requested from gh when listing or viewing workflow runs
+const workflowRunFields = "databaseId,url,displayTitle,workflowName,headBranch,status,conclusion"
+
+// ListWorkflowRuns function to list the recent workflow runs of a repository
+func ListWorkflowRuns(ctx context.Context, options ListOptions) ([]WorkflowRun, error) {
+	// gh run list filters by --status instead of --state
+	args := append([]string{"run", "list"}, repoArgs(options.Hostname, options.RepoName)...)
+	if options.State != "" {
+		args = append(args, "--status", options.State)
+	}
+	if options.Limit > 0 {
+		args = append(args, "--limit", fmt.Sprint(options.Limit))
+	}
+	args = append(args, "--json", workflowRunFields)
+
+	var runs []WorkflowRun
+	if err := runJSON(ctx, args, &runs); err != nil {
+		return nil, err
+	}
+
+	return runs, nil
+}
+
+// GetWorkflowRun function to get the detail of a workflow run and its jobs
+func GetWorkflowRun(ctx context.Context, hostname string, repoName string, runID string) (WorkflowRun, error) {
+	args := append([]string{"run", "view", runID}, repoArgs(hostname, repoName)...)
+	args = append(args, "--json", workflowRunFields+",jobs")
+
+	var run WorkflowRun
+	if err := runJSON(ctx, args, &run); err != nil {
+		return WorkflowRun{}, err
+	}
+
+	return run, nil
+}
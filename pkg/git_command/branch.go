@@ -0,0 +1,125 @@
+package git_command
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/coding-for-fun-org/lazygithub/pkg/command_runner"
+)
+
+// BranchStatus struct to represent how a local branch relates to its upstream
+type BranchStatus struct {
+	// Upstream is the remote tracking branch like "origin/feature", empty when the branch has none
+	Upstream string
+	// Remote is the remote of the upstream
+	Remote string
+	// UpstreamRef is the ref of the upstream on the remote like "refs/heads/feature"
+	UpstreamRef string
+	// Gone is true when the upstream was deleted on the remote
+	Gone   bool
+	Ahead  int
+	Behind int
+}
+
+// GetBranchStatus function to get the upstream of a local branch and how many commits it is ahead of and behind it.
+// The counts come from the remote tracking branch, so they are as fresh as the last fetch.
+// It returns false when there is no local branch with that name.
+func GetBranchStatus(ctx context.Context, branch string) (BranchStatus, bool, error) {
+	args := []string{
+		"for-each-ref",
+		"--format=%(refname)%00%(upstream:short)%00%(upstream:remotename)%00%(upstream:remoteref)%00%(upstream:track)",
+		"refs/heads/" + branch,
+	}
+	output, err := command_runner.Output(ctx, "git", args, nil)
+	if err != nil {
+		return BranchStatus{}, false, err
+	}
+
+	for _, line := range strings.Split(string(output), "\n") {
+		// The pattern also matches "feature/part" when there is no "feature" branch
+		fields := strings.Split(line, "\x00")
+		if len(fields) != 5 || fields[0] != "refs/heads/"+branch {
+			continue
+		}
+
+		status := BranchStatus{Upstream: fields[1], Remote: fields[2], UpstreamRef: fields[3]}
+		if err := parseTrack(fields[4], &status); err != nil {
+			return BranchStatus{}, false, &command_runner.DecodeError{Name: "git", Args: args, Output: line, Err: err}
+		}
+
+		return status, true, nil
+	}
+
+	return BranchStatus{}, false, nil
+}
+
+// RefExists function to check whether a ref like "refs/remotes/origin/main" is in the local repository
+func RefExists(ctx context.Context, ref string) (bool, error) {
+	output, err := command_runner.Output(ctx, "git", []string{"for-each-ref", "--format=%(refname)", ref}, nil)
+	if err != nil {
+		return false, err
+	}
+
+	for _, line := range strings.Split(string(output), "\n") {
+		// The pattern also matches the refs below it
+		if line == ref {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// parseTrack function to read the "[ahead 1, behind 2]" or "[gone]" tracking information of for-each-ref
+func parseTrack(track string, status *BranchStatus) error {
+	track = strings.TrimSuffix(strings.TrimPrefix(track, "["), "]")
+	if track == "" {
+		return nil
+	}
+	if track == "gone" {
+		status.Gone = true
+		return nil
+	}
+
+	for _, part := range strings.Split(track, ", ") {
+		var err error
+		switch {
+		case strings.HasPrefix(part, "ahead "):
+			_, err = fmt.Sscanf(part, "ahead %d", &status.Ahead)
+		case strings.HasPrefix(part, "behind "):
+			_, err = fmt.Sscanf(part, "behind %d", &status.Behind)
+		default:
+			err = fmt.Errorf("unknown tracking information %q", part)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// PushOptions struct to represent the options for pushing a branch
+type PushOptions struct {
+	// SetUpstream makes the pushed branch the upstream of the local one
+	SetUpstream bool
+	// ForceWithLease overwrites the remote branch, but only when it is still where the remote tracking branch says
+	ForceWithLease bool
+}
+
+// PushBranch function to push a local branch to the branch with the same name on the remote
+func PushBranch(ctx context.Context, remote string, branch string, options PushOptions) error {
+	args := []string{"push"}
+	if options.SetUpstream {
+		args = append(args, "--set-upstream")
+	}
+	if options.ForceWithLease {
+		args = append(args, "--force-with-lease")
+	}
+	args = append(args, remote, "refs/heads/"+branch+":refs/heads/"+branch)
+
+	_, err := command_runner.Output(ctx, "git", args, nil)
+
+	return err
+}
@@ -0,0 +1,95 @@
+package git_command
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/coding-for-fun-org/lazygithub/pkg/command_runner"
+)
+
+// Trailer struct to represent a "Key: value" line at the end of a commit message, like "Signed-off-by: Alice <a@b>"
+type Trailer struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// Commit struct to represent a commit of a branch, read from the local repository or from GitHub
+type Commit struct {
+	Sha     string `json:"sha"`
+	Message string `json:"message"`
+	Author  string `json:"author"`
+	// Trailers are parsed from the last paragraph of the message, nil when it has none
+	Trailers []Trailer `json:"trailers"`
+}
+
+// trailerPattern matches the first line of a trailer, the key is made of letters, digits and dashes
+var trailerPattern = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9-]*)[ \t]*:[ \t]*(.*)$`)
+
+// ParseTrailers function to get the trailers of a commit message.
+// Like git, only the last paragraph is read and only when the subject is not the whole message,
+// every line of it has to be a trailer, and a line starting with whitespace continues the one before it.
+func ParseTrailers(message string) []Trailer {
+	paragraphs := strings.Split(strings.TrimSpace(strings.ReplaceAll(message, "\r\n", "\n")), "\n\n")
+	if len(paragraphs) < 2 {
+		return nil
+	}
+
+	trailers := make([]Trailer, 0)
+	for _, line := range strings.Split(paragraphs[len(paragraphs)-1], "\n") {
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(trailers) > 0 {
+			last := &trailers[len(trailers)-1]
+			last.Value += " " + strings.TrimSpace(line)
+			continue
+		}
+
+		match := trailerPattern.FindStringSubmatch(line)
+		if match == nil {
+			return nil
+		}
+		trailers = append(trailers, Trailer{Key: match[1], Value: strings.TrimSpace(match[2])})
+	}
+
+	return trailers
+}
+
+// logFieldSeparator separates the fields of a commit in the output of git log, it does not appear in messages
+const logFieldSeparator = "\x1f"
+
+// ListCommits function to list the commits of head since it forked from base, the oldest first.
+// These are the commits a pull request from head into base shows, without asking GitHub.
+func ListCommits(ctx context.Context, baseRef string, headRef string) ([]Commit, error) {
+	output, err := command_runner.Output(ctx, "git", []string{"merge-base", baseRef, headRef}, nil)
+	if err != nil {
+		return nil, err
+	}
+	mergeBase := strings.TrimSpace(string(output))
+
+	output, err = command_runner.Output(
+		ctx,
+		"git",
+		[]string{"log", "--reverse", "-z", "--format=%H%x1f%an%x1f%B", mergeBase + ".." + headRef},
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	commits := make([]Commit, 0)
+	// -z ends every commit with a NUL
+	for _, entry := range strings.Split(string(output), "\x00") {
+		fields := strings.SplitN(entry, logFieldSeparator, 3)
+		if len(fields) != 3 {
+			continue
+		}
+		message := strings.TrimRight(fields[2], "\n")
+		commits = append(commits, Commit{
+			Sha:      fields[0],
+			Message:  message,
+			Author:   fields[1],
+			Trailers: ParseTrailers(message),
+		})
+	}
+
+	return commits, nil
+}
@@ -0,0 +1,78 @@
+package git_command
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/coding-for-fun-org/lazygithub/pkg/command_runner"
+)
+
+// ListChangedFiles function to list the paths changed on head since it forked from base,
+// the same files a pull request from head into base shows
+func ListChangedFiles(ctx context.Context, baseBranch string, headBranch string) ([]string, error) {
+	output, err := command_runner.Output(
+		ctx,
+		"git",
+		[]string{"diff", "--name-only", "-z", baseBranch + "..." + headBranch},
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]string, 0)
+	for _, file := range strings.Split(string(output), "\x00") {
+		if file != "" {
+			files = append(files, file)
+		}
+	}
+
+	return files, nil
+}
+
+// DiffStats struct to represent the size of the changes of head since it forked from base
+type DiffStats struct {
+	Files     int
+	Additions int
+	Deletions int
+}
+
+// GetDiffStats function to count the changed files and lines of head since it forked from base.
+// Binary files are counted as changed files without lines.
+func GetDiffStats(ctx context.Context, baseBranch string, headBranch string) (DiffStats, error) {
+	args := []string{"diff", "--numstat", baseBranch + "..." + headBranch}
+	output, err := command_runner.Output(ctx, "git", args, nil)
+	if err != nil {
+		return DiffStats{}, err
+	}
+
+	stats := DiffStats{}
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		// Each line looks like "10	2	path", with "-" for the counts of a binary file
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		stats.Files++
+		if fields[0] == "-" {
+			continue
+		}
+
+		additions, errAdditions := strconv.Atoi(fields[0])
+		deletions, errDeletions := strconv.Atoi(fields[1])
+		if errAdditions != nil || errDeletions != nil {
+			return DiffStats{}, &command_runner.DecodeError{
+				Name:   "git",
+				Args:   args,
+				Output: line,
+				Err:    fmt.Errorf("unexpected line counts %q and %q", fields[0], fields[1]),
+			}
+		}
+		stats.Additions += additions
+		stats.Deletions += deletions
+	}
+
+	return stats, nil
+}
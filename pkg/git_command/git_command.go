@@ -1,10 +1,10 @@
 package git_command
 
 import (
-	"encoding/json"
-	"log"
-	"os/exec"
+	"context"
 	"strings"
+
+	"github.com/coding-for-fun-org/lazygithub/pkg/command_runner"
 )
 
 type ListLatestBranchesResponse struct {
@@ -13,17 +13,16 @@ type ListLatestBranchesResponse struct {
 	Date   string `json:"date"`
 }
 
-func ListLatestBranches() []ListLatestBranchesResponse {
-	cmd := exec.Command(
-		"git",
+func ListLatestBranches(ctx context.Context) ([]ListLatestBranchesResponse, error) {
+	args := []string{
 		"for-each-ref",
 		"refs/heads/",
 		"--sort=-committerdate",
 		"--format={\"ref\": \"%(refname:short)\", \"commit\": \"%(objectname)\", \"date\": \"%(authordate:iso8601)\"}",
-	)
-	output, err := cmd.Output()
+	}
+	output, err := command_runner.Output(ctx, "git", args, nil)
 	if err != nil {
-		log.Fatalf("Failed to execute git command: %v", err)
+		return nil, err
 	}
 
 	// Convert output to string and split into lines
@@ -33,10 +32,10 @@ func ListLatestBranches() []ListLatestBranchesResponse {
 
 	// Parse the JSON output into a slice of Branch structs
 	var bs []ListLatestBranchesResponse
-	err = json.Unmarshal([]byte(jsonArray), &bs)
+	err = command_runner.DecodeJSON("git", args, []byte(jsonArray), &bs)
 	if err != nil {
-		log.Fatalf("Failed to unmarshal JSON: %v", err)
+		return nil, err
 	}
 
-	return bs
+	return bs, nil
 }
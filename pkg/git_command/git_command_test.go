@@ -0,0 +1,365 @@
+package git_command
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/coding-for-fun-org/lazygithub/pkg/command_runner"
+)
+
+func TestListLatestBranches(t *testing.T) {
+	forEachRefArgs := []string{
+		"for-each-ref",
+		"refs/heads/",
+		"--sort=-committerdate",
+		"--format={\"ref\": \"%(refname:short)\", \"commit\": \"%(objectname)\", \"date\": \"%(authordate:iso8601)\"}",
+	}
+
+	tests := []struct {
+		name     string
+		response command_runner.FakeResponse
+		want     []ListLatestBranchesResponse
+		wantKind error
+	}{
+		{
+			name: "base test case",
+			response: command_runner.FakeResponse{
+				Stdout: "{\"ref\": \"feature\", \"commit\": \"abc\", \"date\": \"2024-10-02 10:00:00 +0900\"}\n" +
+					"{\"ref\": \"main\", \"commit\": \"def\", \"date\": \"2024-10-01 10:00:00 +0900\"}\n",
+			},
+			want: []ListLatestBranchesResponse{
+				{Ref: "feature", Commit: "abc", Date: "2024-10-02 10:00:00 +0900"},
+				{Ref: "main", Commit: "def", Date: "2024-10-01 10:00:00 +0900"},
+			},
+		},
+		{
+			name:     "no branches",
+			response: command_runner.FakeResponse{Stdout: ""},
+			want:     []ListLatestBranchesResponse{},
+		},
+		{
+			name: "not a git repository",
+			response: command_runner.FakeResponse{
+				Stderr:   "fatal: not a git repository (or any of the parent directories): .git",
+				ExitCode: 128,
+			},
+			wantKind: command_runner.ErrNotGitRepository,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := command_runner.NewFakeRunner().Add(tt.response, "git", forEachRefArgs...)
+			defer command_runner.SetDefault(runner)()
+
+			got, err := ListLatestBranches(context.Background())
+			if tt.wantKind != nil {
+				if !errors.Is(err, tt.wantKind) {
+					t.Errorf("ListLatestBranches() error = %v, want kind %v", err, tt.wantKind)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("ListLatestBranches() error = %v", err)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ListLatestBranches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListChangedFiles(t *testing.T) {
+	runner := command_runner.NewFakeRunner().Add(
+		command_runner.FakeResponse{Stdout: "README.md\x00pkg/a b.go\x00"},
+		"git", "diff", "--name-only", "-z", "main...feature",
+	)
+	defer command_runner.SetDefault(runner)()
+
+	got, err := ListChangedFiles(context.Background(), "main", "feature")
+	if err != nil {
+		t.Fatalf("ListChangedFiles() error = %v", err)
+	}
+	if want := []string{"README.md", "pkg/a b.go"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ListChangedFiles() = %v, want %v", got, want)
+	}
+}
+
+func TestGetDiffStats(t *testing.T) {
+	runner := command_runner.NewFakeRunner().Add(
+		command_runner.FakeResponse{Stdout: "10\t2\tREADME.md\n-\t-\tlogo.png\n3\t0\tpkg/a b.go\n"},
+		"git", "diff", "--numstat", "main...feature",
+	)
+	defer command_runner.SetDefault(runner)()
+
+	got, err := GetDiffStats(context.Background(), "main", "feature")
+	if err != nil {
+		t.Fatalf("GetDiffStats() error = %v", err)
+	}
+	if want := (DiffStats{Files: 3, Additions: 13, Deletions: 2}); got != want {
+		t.Errorf("GetDiffStats() = %v, want %v", got, want)
+	}
+}
+
+func TestGetBranchStatus(t *testing.T) {
+	forEachRefArgs := []string{
+		"for-each-ref",
+		"--format=%(refname)%00%(upstream:short)%00%(upstream:remotename)%00%(upstream:remoteref)%00%(upstream:track)",
+		"refs/heads/feature",
+	}
+	tests := []struct {
+		name   string
+		stdout string
+		want   BranchStatus
+		wantOk bool
+	}{
+		{
+			name:   "never pushed",
+			stdout: "refs/heads/feature\x00\x00\x00\x00\n",
+			want:   BranchStatus{},
+			wantOk: true,
+		},
+		{
+			name:   "up to date",
+			stdout: "refs/heads/feature\x00origin/feature\x00origin\x00refs/heads/feature\x00\n",
+			want:   BranchStatus{Upstream: "origin/feature", Remote: "origin", UpstreamRef: "refs/heads/feature"},
+			wantOk: true,
+		},
+		{
+			name:   "diverged after a rebase",
+			stdout: "refs/heads/feature\x00origin/feature\x00origin\x00refs/heads/feature\x00[ahead 2, behind 3]\n",
+			want:   BranchStatus{Upstream: "origin/feature", Remote: "origin", UpstreamRef: "refs/heads/feature", Ahead: 2, Behind: 3},
+			wantOk: true,
+		},
+		{
+			name:   "upstream deleted on the remote",
+			stdout: "refs/heads/feature\x00origin/feature\x00origin\x00refs/heads/feature\x00[gone]\n",
+			want:   BranchStatus{Upstream: "origin/feature", Remote: "origin", UpstreamRef: "refs/heads/feature", Gone: true},
+			wantOk: true,
+		},
+		{
+			name:   "only a branch below the name",
+			stdout: "refs/heads/feature/part\x00\x00\x00\x00\n",
+			wantOk: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := command_runner.NewFakeRunner().Add(command_runner.FakeResponse{Stdout: tt.stdout}, "git", forEachRefArgs...)
+			defer command_runner.SetDefault(runner)()
+
+			got, ok, err := GetBranchStatus(context.Background(), "feature")
+			if err != nil {
+				t.Fatalf("GetBranchStatus() error = %v", err)
+			}
+			if ok != tt.wantOk || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetBranchStatus() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestRefExists(t *testing.T) {
+	tests := []struct {
+		name   string
+		stdout string
+		want   bool
+	}{
+		{
+			name:   "fetched",
+			stdout: "refs/remotes/origin/main\n",
+			want:   true,
+		},
+		{
+			name:   "only a ref below the name",
+			stdout: "refs/remotes/origin/main/part\n",
+			want:   false,
+		},
+		{
+			name:   "never fetched",
+			stdout: "",
+			want:   false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := command_runner.NewFakeRunner().Add(
+				command_runner.FakeResponse{Stdout: tt.stdout},
+				"git", "for-each-ref", "--format=%(refname)", "refs/remotes/origin/main",
+			)
+			defer command_runner.SetDefault(runner)()
+
+			got, err := RefExists(context.Background(), "refs/remotes/origin/main")
+			if err != nil {
+				t.Fatalf("RefExists() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("RefExists() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPushBranch(t *testing.T) {
+	runner := command_runner.NewFakeRunner().Add(
+		command_runner.FakeResponse{},
+		"git", "push", "--set-upstream", "--force-with-lease", "origin", "refs/heads/feature:refs/heads/feature",
+	)
+	defer command_runner.SetDefault(runner)()
+
+	err := PushBranch(context.Background(), "origin", "feature", PushOptions{SetUpstream: true, ForceWithLease: true})
+	if err != nil {
+		t.Errorf("PushBranch() error = %v", err)
+	}
+}
+
+func TestParseTrailers(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    []Trailer
+	}{
+		{
+			name:    "trailers after the body",
+			message: "feat: login\n\nThe login page\n\nRefs: ABC-123\nCo-authored-by: Bob\n  <bob@example.com>\n",
+			want: []Trailer{
+				{Key: "Refs", Value: "ABC-123"},
+				{Key: "Co-authored-by", Value: "Bob <bob@example.com>"},
+			},
+		},
+		{
+			name:    "subject only",
+			message: "Refs: ABC-123",
+			want:    nil,
+		},
+		{
+			name:    "last paragraph is not made of trailers",
+			message: "feat: login\n\nRefs: ABC-123\nand some text",
+			want:    nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseTrailers(tt.message); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseTrailers() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListCommits(t *testing.T) {
+	runner := command_runner.NewFakeRunner().
+		Add(command_runner.FakeResponse{Stdout: "base\n"}, "git", "merge-base", "main", "feature").
+		Add(
+			command_runner.FakeResponse{Stdout: "abc\x1fAlice\x1ffeat: first\n\x00def\x1fBob\x1ffix: second\n\nbody\n\x00"},
+			"git", "log", "--reverse", "-z", "--format=%H%x1f%an%x1f%B", "base..feature",
+		)
+	defer command_runner.SetDefault(runner)()
+
+	got, err := ListCommits(context.Background(), "main", "feature")
+	if err != nil {
+		t.Fatalf("ListCommits() error = %v", err)
+	}
+	want := []Commit{
+		{Sha: "abc", Message: "feat: first", Author: "Alice"},
+		{Sha: "def", Message: "fix: second\n\nbody", Author: "Bob"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ListCommits() = %v, want %v", got, want)
+	}
+}
+
+func TestGetCurrentBranch(t *testing.T) {
+	symbolicRefArgs := []string{"symbolic-ref", "--quiet", "--short", "HEAD"}
+
+	tests := []struct {
+		name     string
+		response command_runner.FakeResponse
+		want     string
+	}{
+		{
+			name:     "branch checked out",
+			response: command_runner.FakeResponse{Stdout: "feature\n"},
+			want:     "feature",
+		},
+		{
+			name:     "detached HEAD",
+			response: command_runner.FakeResponse{ExitCode: 1},
+			want:     "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := command_runner.NewFakeRunner().Add(tt.response, "git", symbolicRefArgs...)
+			defer command_runner.SetDefault(runner)()
+
+			got, err := GetCurrentBranch(context.Background())
+			if err != nil {
+				t.Fatalf("GetCurrentBranch() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("GetCurrentBranch() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindStack(t *testing.T) {
+	between := func(ref string) []string {
+		return []string{"for-each-ref", "--format=%(refname:short)", "--merged=" + ref, "--no-merged=origin/main", "refs/heads/"}
+	}
+	count := func(revisions string) []string {
+		return []string{"rev-list", "--count", revisions}
+	}
+
+	tests := []struct {
+		name     string
+		head     string
+		branches []string
+		setup    func(runner *command_runner.FakeRunner)
+		want     []string
+	}{
+		{
+			name:     "chain of branches",
+			head:     "feature-3",
+			branches: []string{"feature-3", "feature-2", "feature-1", "main"},
+			setup: func(runner *command_runner.FakeRunner) {
+				// scratch is not one of the given branches
+				runner.Add(command_runner.FakeResponse{Stdout: "feature-1\nfeature-2\nfeature-3\nscratch\n"}, "git", between("feature-3")...)
+				runner.Add(command_runner.FakeResponse{Stdout: "3\n"}, "git", count("feature-1..feature-3")...)
+				runner.Add(command_runner.FakeResponse{Stdout: "1\n"}, "git", count("feature-2..feature-3")...)
+				runner.Add(command_runner.FakeResponse{Stdout: "feature-1\nfeature-2\n"}, "git", between("feature-2")...)
+				runner.Add(command_runner.FakeResponse{Stdout: "2\n"}, "git", count("feature-1..feature-2")...)
+				runner.Add(command_runner.FakeResponse{Stdout: "feature-1\n"}, "git", between("feature-1")...)
+			},
+			want: []string{"feature-1", "feature-2", "feature-3"},
+		},
+		{
+			name:     "branch at the same commit is not a parent",
+			head:     "feature",
+			branches: []string{"feature", "copy", "main"},
+			setup: func(runner *command_runner.FakeRunner) {
+				runner.Add(command_runner.FakeResponse{Stdout: "copy\nfeature\n"}, "git", between("feature")...)
+				runner.Add(command_runner.FakeResponse{Stdout: "0\n"}, "git", count("copy..feature")...)
+			},
+			want: []string{"feature"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := command_runner.NewFakeRunner()
+			tt.setup(runner)
+			defer command_runner.SetDefault(runner)()
+
+			got, err := FindStack(context.Background(), tt.head, "origin/main", tt.branches)
+			if err != nil {
+				t.Fatalf("FindStack() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FindStack() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
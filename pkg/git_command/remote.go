@@ -0,0 +1,86 @@
+package git_command
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/coding-for-fun-org/lazygithub/pkg/command_runner"
+)
+
+// Remote struct to represent a git remote that points to a GitHub repository
+type Remote struct {
+	Name  string
+	Host  string
+	Owner string
+	Repo  string
+}
+
+// ParseRemoteURL function to get the host, owner and repository name from a remote URL.
+// It understands https://, ssh://, git:// and the scp like git@host:owner/repo syntax.
+func ParseRemoteURL(remoteURL string) (string, string, string, bool) {
+	host := ""
+	path := ""
+
+	if strings.Contains(remoteURL, "://") {
+		u, err := url.Parse(remoteURL)
+		if err != nil {
+			return "", "", "", false
+		}
+		host = u.Hostname()
+		path = u.Path
+	} else {
+		// scp like syntax: [user@]host:path
+		hostPart, pathPart, found := strings.Cut(remoteURL, ":")
+		if !found {
+			return "", "", "", false
+		}
+		if at := strings.LastIndex(hostPart, "@"); at >= 0 {
+			hostPart = hostPart[at+1:]
+		}
+		host = hostPart
+		path = pathPart
+	}
+
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if host == "" || len(parts) < 2 {
+		return "", "", "", false
+	}
+	owner := parts[len(parts)-2]
+	repo := strings.TrimSuffix(parts[len(parts)-1], ".git")
+	if owner == "" || repo == "" {
+		return "", "", "", false
+	}
+
+	// ssh.github.com is the ssh over https port host of github.com
+	if strings.EqualFold(host, "ssh.github.com") {
+		host = "github.com"
+	}
+
+	return strings.ToLower(host), owner, repo, true
+}
+
+// ListRemotes function to list the remotes of the repository that point to a repository on a host
+func ListRemotes(ctx context.Context) ([]Remote, error) {
+	output, err := command_runner.Output(ctx, "git", []string{"remote", "-v"}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	remotes := make([]Remote, 0)
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		// Each line looks like "origin	git@github.com:owner/repo.git (fetch)"
+		fields := strings.Fields(line)
+		if len(fields) != 3 || fields[2] != "(fetch)" {
+			continue
+		}
+
+		host, owner, repo, ok := ParseRemoteURL(fields[1])
+		if !ok {
+			continue
+		}
+		remotes = append(remotes, Remote{Name: fields[0], Host: host, Owner: owner, Repo: repo})
+	}
+
+	return remotes, nil
+}
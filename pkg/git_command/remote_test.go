@@ -0,0 +1,32 @@
+package git_command
+
+import "testing"
+
+func TestParseRemoteURL(t *testing.T) {
+	tests := []struct {
+		name      string
+		remoteURL string
+		wantHost  string
+		wantOwner string
+		wantRepo  string
+		wantOk    bool
+	}{
+		{name: "https", remoteURL: "https://github.com/owner/repo.git", wantHost: "github.com", wantOwner: "owner", wantRepo: "repo", wantOk: true},
+		{name: "https without .git", remoteURL: "https://github.com/owner/repo", wantHost: "github.com", wantOwner: "owner", wantRepo: "repo", wantOk: true},
+		{name: "scp like", remoteURL: "git@github.example.com:owner/repo.git", wantHost: "github.example.com", wantOwner: "owner", wantRepo: "repo", wantOk: true},
+		{name: "ssh", remoteURL: "ssh://git@ssh.github.com:443/owner/repo.git", wantHost: "github.com", wantOwner: "owner", wantRepo: "repo", wantOk: true},
+		{name: "local path", remoteURL: "/srv/git/repo.git", wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			host, owner, repo, ok := ParseRemoteURL(tt.remoteURL)
+			if ok != tt.wantOk || host != tt.wantHost || owner != tt.wantOwner || repo != tt.wantRepo {
+				t.Errorf(
+					"ParseRemoteURL() = %v, %v, %v, %v, want %v, %v, %v, %v",
+					host, owner, repo, ok,
+					tt.wantHost, tt.wantOwner, tt.wantRepo, tt.wantOk,
+				)
+			}
+		})
+	}
+}
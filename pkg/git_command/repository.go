@@ -0,0 +1,18 @@
+package git_command
+
+import (
+	"context"
+	"strings"
+
+	"github.com/coding-for-fun-org/lazygithub/pkg/command_runner"
+)
+
+// GetRepositoryRoot function to get the absolute path of the top level directory of the working tree
+func GetRepositoryRoot(ctx context.Context) (string, error) {
+	output, err := command_runner.Output(ctx, "git", []string{"rev-parse", "--show-toplevel"}, nil)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
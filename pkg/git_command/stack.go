@@ -0,0 +1,97 @@
+package git_command
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/coding-for-fun-org/lazygithub/pkg/command_runner"
+)
+
+// GetCurrentBranch function to get the name of the checked out branch, empty when HEAD is detached
+func GetCurrentBranch(ctx context.Context) (string, error) {
+	output, err := command_runner.Output(ctx, "git", []string{"symbolic-ref", "--quiet", "--short", "HEAD"}, nil)
+	if err != nil {
+		var commandErr *command_runner.CommandError
+		// symbolic-ref exits with 1 and prints nothing when HEAD is detached
+		if errors.As(err, &commandErr) && commandErr.ExitCode == 1 {
+			return "", nil
+		}
+		return "", err
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
+
+// listBranchesBetween function to list the local branches whose tip is reachable from ref but not from baseRef
+func listBranchesBetween(ctx context.Context, ref string, baseRef string) ([]string, error) {
+	output, err := command_runner.Output(
+		ctx,
+		"git",
+		[]string{"for-each-ref", "--format=%(refname:short)", "--merged=" + ref, "--no-merged=" + baseRef, "refs/heads/"},
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	branches := make([]string, 0)
+	for _, line := range strings.Split(string(output), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			branches = append(branches, line)
+		}
+	}
+
+	return branches, nil
+}
+
+// countCommits function to count the commits reachable from ref but not from baseRef
+func countCommits(ctx context.Context, baseRef string, ref string) (int, error) {
+	args := []string{"rev-list", "--count", baseRef + ".." + ref}
+	output, err := command_runner.Output(ctx, "git", args, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := strconv.Atoi(strings.TrimSpace(string(output)))
+	if err != nil {
+		return 0, &command_runner.DecodeError{Name: "git", Args: args, Output: string(output), Err: err}
+	}
+
+	return count, nil
+}
+
+// FindStack function to find the chain of branches head is stacked on, from the one closest to baseRef up to head.
+// Only the given branches are considered. The parent of a branch is the nearest of them whose tip is an ancestor of it
+// and is not part of baseRef yet, a branch pointing at the same commit can not be told apart and is skipped.
+func FindStack(ctx context.Context, head string, baseRef string, branches []string) ([]string, error) {
+	stack := []string{head}
+	for current := head; ; {
+		candidates, err := listBranchesBetween(ctx, current, baseRef)
+		if err != nil {
+			return nil, err
+		}
+
+		parent, parentDistance := "", 0
+		for _, candidate := range candidates {
+			if !slices.Contains(branches, candidate) || slices.Contains(stack, candidate) {
+				continue
+			}
+			distance, err := countCommits(ctx, candidate, current)
+			if err != nil {
+				return nil, err
+			}
+			if distance > 0 && (parent == "" || distance < parentDistance) {
+				parent, parentDistance = candidate, distance
+			}
+		}
+		if parent == "" {
+			return stack, nil
+		}
+
+		stack = append([]string{parent}, stack...)
+		current = parent
+	}
+}
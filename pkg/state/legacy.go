@@ -0,0 +1,70 @@
+package state
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// legacyReviewersFileName is the CSV file in the home directory that stored the latest reviewers before the state file
+const legacyReviewersFileName = ".__reviewers.csv"
+
+// legacyReviewersFilePath function to get the path of the CSV file of the previous versions
+func legacyReviewersFilePath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+
+	return filepath.Join(home, legacyReviewersFileName), nil
+}
+
+// migrateLegacy method to build the first state from the CSV file of the previous versions.
+// Each record is the repository node ID followed by the comma separated reviewers,
+// and becomes a single selection dated with the modification time of the file.
+func (s *Store) migrateLegacy() (State, bool, error) {
+	state := State{Version: Version}
+	if s.legacyReviewersPath == "" {
+		return state, false, nil
+	}
+
+	file, err := os.Open(s.legacyReviewersPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, false, nil
+	}
+	if err != nil {
+		return State{}, false, fmt.Errorf("failed to open %s: %w", s.legacyReviewersPath, err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return State{}, false, fmt.Errorf("failed to read %s: %w", s.legacyReviewersPath, err)
+	}
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return State{}, false, fmt.Errorf("failed to read %s: %w", s.legacyReviewersPath, err)
+	}
+
+	for _, record := range records {
+		if len(record) < 2 || record[0] == "" {
+			continue
+		}
+
+		reviewers := make([]string, 0)
+		for _, reviewer := range strings.Split(record[1], ",") {
+			if reviewer != "" {
+				reviewers = append(reviewers, reviewer)
+			}
+		}
+		state.Repository(record[0]).AddReviewerSelection(reviewers, info.ModTime())
+	}
+
+	return state, true, nil
+}
@@ -0,0 +1,61 @@
+package state
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// ReviewerRank struct to represent how strongly a reviewer is suggested
+type ReviewerRank struct {
+	Login string
+	// Score is the number of times the reviewer was chosen, each choice weighted by its age when choices decay
+	Score      float64
+	LastPicked time.Time
+}
+
+// RankReviewers method to rank the reviewers that were ever chosen, the most suggested first.
+// Every choice counts 1, or halves every halfLife when halfLife is positive,
+// so both how often and how recently a reviewer was chosen raise the score.
+// Equal scores are ordered by the latest choice, then by login.
+func (r *RepositoryState) RankReviewers(now time.Time, halfLife time.Duration) []ReviewerRank {
+	rankByLogin := make(map[string]*ReviewerRank)
+	for _, selection := range r.ReviewerSelections {
+		weight := 1.0
+		if halfLife > 0 {
+			age := now.Sub(selection.At)
+			if age < 0 {
+				age = 0
+			}
+			weight = math.Pow(0.5, float64(age)/float64(halfLife))
+		}
+
+		for _, login := range selection.Reviewers {
+			rank, ok := rankByLogin[login]
+			if !ok {
+				rank = &ReviewerRank{Login: login}
+				rankByLogin[login] = rank
+			}
+			rank.Score += weight
+			if selection.At.After(rank.LastPicked) {
+				rank.LastPicked = selection.At
+			}
+		}
+	}
+
+	ranks := make([]ReviewerRank, 0, len(rankByLogin))
+	for _, rank := range rankByLogin {
+		ranks = append(ranks, *rank)
+	}
+	sort.Slice(ranks, func(i, j int) bool {
+		if ranks[i].Score != ranks[j].Score {
+			return ranks[i].Score > ranks[j].Score
+		}
+		if !ranks[i].LastPicked.Equal(ranks[j].LastPicked) {
+			return ranks[i].LastPicked.After(ranks[j].LastPicked)
+		}
+		return ranks[i].Login < ranks[j].Login
+	})
+
+	return ranks
+}
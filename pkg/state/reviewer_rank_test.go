@@ -0,0 +1,70 @@
+package state
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRepositoryState_RankReviewers(t *testing.T) {
+	now := time.Date(2024, 10, 31, 0, 0, 0, 0, time.UTC)
+	daysAgo := func(days int) time.Time {
+		return now.AddDate(0, 0, -days)
+	}
+	repository := &RepositoryState{
+		ReviewerSelections: []ReviewerSelection{
+			{Reviewers: []string{"alice", "bob"}, At: daysAgo(90)},
+			{Reviewers: []string{"alice"}, At: daysAgo(60)},
+			{Reviewers: []string{"alice", "carol"}, At: daysAgo(30)},
+			{Reviewers: []string{"dave"}, At: daysAgo(1)},
+			{Reviewers: []string{"dave"}, At: daysAgo(0)},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		halfLife time.Duration
+		want     []string
+	}{
+		{
+			name:     "without decay the most frequent first, then the most recent",
+			halfLife: 0,
+			want:     []string{"alice", "dave", "carol", "bob"},
+		},
+		{
+			name:     "with decay recent choices outweigh old ones",
+			halfLife: 14 * 24 * time.Hour,
+			want:     []string{"dave", "alice", "carol", "bob"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ranks := repository.RankReviewers(now, tt.halfLife)
+			got := make([]string, 0, len(ranks))
+			for _, rank := range ranks {
+				got = append(got, rank.Login)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("RankReviewers() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRepositoryState_RankReviewers_recentOutranksFrequent(t *testing.T) {
+	now := time.Date(2024, 10, 31, 0, 0, 0, 0, time.UTC)
+	repository := &RepositoryState{
+		ReviewerSelections: []ReviewerSelection{
+			{Reviewers: []string{"alice"}, At: now.AddDate(0, 0, -120)},
+			{Reviewers: []string{"alice"}, At: now.AddDate(0, 0, -110)},
+			{Reviewers: []string{"alice"}, At: now.AddDate(0, 0, -100)},
+			{Reviewers: []string{"bob"}, At: now.AddDate(0, 0, -2)},
+		},
+	}
+
+	// The default half-life of the config
+	ranks := repository.RankReviewers(now, 30*24*time.Hour)
+	if len(ranks) != 2 || ranks[0].Login != "bob" {
+		t.Errorf("RankReviewers() = %v, want bob picked once recently before alice picked three times long ago", ranks)
+	}
+}
@@ -0,0 +1,241 @@
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+)
+
+// statePathEnv is the environment variable that overrides the location of the state file
+const statePathEnv = "LAZYGITHUB_STATE"
+
+// Version is the version of the state file written by this build.
+// Files of an older version are upgraded when they are loaded.
+const Version = 1
+
+// maxSelections is how many reviewer selections are kept per repository
+const maxSelections = 100
+
+// ReviewerSelection struct to represent the reviewers chosen for one pull request
+type ReviewerSelection struct {
+	Reviewers []string  `json:"reviewers"`
+	At        time.Time `json:"at"`
+}
+
+// RepositoryState struct to represent what is remembered about one repository
+type RepositoryState struct {
+	// Name is "host/owner/name" at the time of the last update, it is only informative
+	Name string `json:"name"`
+	// ReviewerSelections is the history of the chosen reviewers, the oldest first
+	ReviewerSelections []ReviewerSelection `json:"reviewerSelections"`
+	// LatestMetadata is what was chosen for the last pull request, nil when nothing was chosen yet
+	LatestMetadata *MetadataSelection `json:"latestMetadata,omitempty"`
+}
+
+// MetadataSelection struct to represent the labels, assignees, milestone and projects chosen for one pull request.
+// They are kept by name rather than node ID so that the file stays readable.
+type MetadataSelection struct {
+	Labels    []string `json:"labels"`
+	Assignees []string `json:"assignees"`
+	Milestone string   `json:"milestone,omitempty"`
+	Projects  []string `json:"projects"`
+}
+
+// LatestReviewers method to get the reviewers of the last selection, nil when there is none
+func (r *RepositoryState) LatestReviewers() []string {
+	if len(r.ReviewerSelections) == 0 {
+		return nil
+	}
+
+	return r.ReviewerSelections[len(r.ReviewerSelections)-1].Reviewers
+}
+
+// AddReviewerSelection method to record the reviewers chosen at the given time, dropping the oldest selections
+func (r *RepositoryState) AddReviewerSelection(reviewers []string, at time.Time) {
+	r.ReviewerSelections = append(r.ReviewerSelections, ReviewerSelection{
+		Reviewers: append([]string{}, reviewers...),
+		At:        at,
+	})
+	if len(r.ReviewerSelections) > maxSelections {
+		r.ReviewerSelections = r.ReviewerSelections[len(r.ReviewerSelections)-maxSelections:]
+	}
+}
+
+// State struct to represent the state file
+type State struct {
+	Version int `json:"version"`
+	// Repositories is keyed by the node ID of the repository, which survives renames and transfers
+	Repositories map[string]*RepositoryState `json:"repositories"`
+}
+
+// Repository method to get the state of a repository, creating it when it does not exist yet
+func (s *State) Repository(id string) *RepositoryState {
+	if s.Repositories == nil {
+		s.Repositories = make(map[string]*RepositoryState)
+	}
+	repository, ok := s.Repositories[id]
+	if !ok {
+		repository = &RepositoryState{}
+		s.Repositories[id] = repository
+	}
+
+	return repository
+}
+
+// Path function to get the path of the state file.
+// It follows the XDG base directory specification, falling back to the user config directory
+// on the systems that do not have a state directory.
+func Path() (string, error) {
+	if path := os.Getenv(statePathEnv); path != "" {
+		return path, nil
+	}
+
+	if dir := os.Getenv("XDG_STATE_HOME"); filepath.IsAbs(dir) {
+		return filepath.Join(dir, "lazygithub", "state.json"), nil
+	}
+
+	if runtime.GOOS != "windows" && runtime.GOOS != "darwin" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get user home directory: %w", err)
+		}
+
+		return filepath.Join(home, ".local", "state", "lazygithub", "state.json"), nil
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user config directory: %w", err)
+	}
+
+	return filepath.Join(dir, "lazygithub", "state.json"), nil
+}
+
+// Store struct to read and update the state file.
+// Updates are serialized with a lock file, so concurrent runs do not lose each other's changes.
+type Store struct {
+	path string
+	// legacyReviewersPath is the CSV file of the previous versions, imported when there is no state file yet
+	legacyReviewersPath string
+}
+
+// NewStore function to create a store for the state file at the default path
+func NewStore() (*Store, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	legacyReviewersPath, err := legacyReviewersFilePath()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Store{path: path, legacyReviewersPath: legacyReviewersPath}, nil
+}
+
+// Load method to read the state, a missing file results in the migrated legacy state or an empty one
+func (s *Store) Load() (State, error) {
+	state, _, err := s.read()
+
+	return state, err
+}
+
+// Update method to change the state with fn and write it back atomically while holding the lock.
+// Nothing is written when fn returns an error.
+func (s *Store) Update(fn func(state *State) error) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return fmt.Errorf("failed to create the state directory: %w", err)
+	}
+
+	unlock, err := lockFile(s.path + ".lock")
+	if err != nil {
+		return fmt.Errorf("failed to lock the state file: %w", err)
+	}
+	defer unlock()
+
+	state, migrated, err := s.read()
+	if err != nil {
+		return err
+	}
+	if err := fn(&state); err != nil {
+		return err
+	}
+	state.Version = Version
+	if err := writeFileAtomic(s.path, state); err != nil {
+		return err
+	}
+
+	if migrated {
+		// The reviewers now live in the state file, the dotfile in the home directory is not needed anymore
+		if err := os.Remove(s.legacyReviewersPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove the migrated %s: %w", s.legacyReviewersPath, err)
+		}
+	}
+
+	return nil
+}
+
+// read method to read and upgrade the state file, reporting whether the legacy file was imported
+func (s *Store) read() (State, bool, error) {
+	content, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s.migrateLegacy()
+	}
+	if err != nil {
+		return State{}, false, fmt.Errorf("failed to read the state file: %w", err)
+	}
+
+	var state State
+	if err := json.Unmarshal(content, &state); err != nil {
+		return State{}, false, fmt.Errorf("failed to parse the state file %s: %w", s.path, err)
+	}
+	if state.Version > Version {
+		return State{}, false, fmt.Errorf(
+			"the state file %s has version %d, which is newer than the supported version %d",
+			s.path,
+			state.Version,
+			Version,
+		)
+	}
+	state.Version = Version
+
+	return state, false, nil
+}
+
+// writeFileAtomic function to write the state next to the file and rename it into place,
+// so that readers never see a partially written file
+func writeFileAtomic(path string, state State) error {
+	content, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal the state: %w", err)
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create the state file: %w", err)
+	}
+	// Removing fails once the file was renamed, which is expected
+	defer os.Remove(file.Name())
+
+	if _, err := file.Write(content); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write the state file: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write the state file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write the state file: %w", err)
+	}
+
+	if err := os.Rename(file.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace the state file: %w", err)
+	}
+
+	return nil
+}
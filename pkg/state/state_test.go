@@ -0,0 +1,185 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTestStore function to create a store in a temporary directory with a legacy file that does not exist
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	return &Store{
+		path:                filepath.Join(dir, "state", "state.json"),
+		legacyReviewersPath: filepath.Join(dir, legacyReviewersFileName),
+	}
+}
+
+func TestPath(t *testing.T) {
+	t.Setenv("LAZYGITHUB_STATE", "")
+	t.Setenv("XDG_STATE_HOME", "/xdg/state")
+
+	got, err := Path()
+	if err != nil || got != filepath.Join("/xdg/state", "lazygithub", "state.json") {
+		t.Errorf("Path() = %v, %v, want the XDG state directory", got, err)
+	}
+
+	t.Setenv("LAZYGITHUB_STATE", "/custom/state.json")
+	got, err = Path()
+	if err != nil || got != "/custom/state.json" {
+		t.Errorf("Path() = %v, %v, want the LAZYGITHUB_STATE path", got, err)
+	}
+}
+
+func TestStore_Update(t *testing.T) {
+	store := newTestStore(t)
+	at := time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	for i, reviewers := range [][]string{{"alice"}, {"alice", "bob"}} {
+		err := store.Update(func(s *State) error {
+			s.Repository("R_1").AddReviewerSelection(reviewers, at.Add(time.Duration(i)*time.Hour))
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Store.Update() error = %v", err)
+		}
+	}
+	err := store.Update(func(s *State) error {
+		s.Repository("R_2").AddReviewerSelection([]string{"carol"}, at)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Store.Update() error = %v", err)
+	}
+
+	got, err := store.Load()
+	if err != nil {
+		t.Fatalf("Store.Load() error = %v", err)
+	}
+	if got.Version != Version {
+		t.Errorf("Store.Load() version = %d, want %d", got.Version, Version)
+	}
+	if len(got.Repositories["R_1"].ReviewerSelections) != 2 {
+		t.Errorf("Store.Load() kept %v, want the history of both selections", got.Repositories["R_1"].ReviewerSelections)
+	}
+	if latest := got.Repositories["R_1"].LatestReviewers(); !reflect.DeepEqual(latest, []string{"alice", "bob"}) {
+		t.Errorf("LatestReviewers() = %v, want [alice bob]", latest)
+	}
+	if latest := got.Repositories["R_2"].LatestReviewers(); !reflect.DeepEqual(latest, []string{"carol"}) {
+		t.Errorf("LatestReviewers() = %v, want the other repository to be kept", latest)
+	}
+}
+
+func TestStore_Update_error(t *testing.T) {
+	store := newTestStore(t)
+
+	wantErr := errors.New("stop")
+	err := store.Update(func(s *State) error {
+		s.Repository("R_1").AddReviewerSelection([]string{"alice"}, time.Now())
+		return wantErr
+	})
+	if !errors.Is(err, wantErr) {
+		t.Errorf("Store.Update() error = %v, want %v", err, wantErr)
+	}
+	if _, err := os.Stat(store.path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Store.Update() wrote the state file, want nothing written")
+	}
+}
+
+func TestStore_Update_concurrent(t *testing.T) {
+	path := newTestStore(t).path
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			// Every run opens its own store like separate processes would
+			store := &Store{path: path}
+			err := store.Update(func(s *State) error {
+				s.Repository(fmt.Sprintf("R_%d", i)).AddReviewerSelection([]string{"alice"}, time.Now())
+				return nil
+			})
+			if err != nil {
+				t.Errorf("Store.Update() error = %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	got, err := (&Store{path: path}).Load()
+	if err != nil {
+		t.Fatalf("Store.Load() error = %v", err)
+	}
+	if len(got.Repositories) != 20 {
+		t.Errorf("Store.Load() has %d repositories, want 20", len(got.Repositories))
+	}
+}
+
+func TestStore_Load_newerVersion(t *testing.T) {
+	store := newTestStore(t)
+	if err := os.MkdirAll(filepath.Dir(store.path), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(store.path, []byte(`{"version": 99}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.Load(); err == nil {
+		t.Errorf("Store.Load() error = nil, want an error for a newer version")
+	}
+}
+
+func TestStore_migrateLegacy(t *testing.T) {
+	store := newTestStore(t)
+	legacy := "R_1,\"alice,bob\"\nR_2,carol\nR_3,\n"
+	if err := os.WriteFile(store.legacyReviewersPath, []byte(legacy), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := store.Load()
+	if err != nil {
+		t.Fatalf("Store.Load() error = %v", err)
+	}
+	want := map[string][]string{"R_1": {"alice", "bob"}, "R_2": {"carol"}, "R_3": {}}
+	for id, reviewers := range want {
+		repository, ok := got.Repositories[id]
+		if !ok || !reflect.DeepEqual(repository.LatestReviewers(), reviewers) {
+			t.Errorf("Store.Load() %s = %v, want %v", id, repository, reviewers)
+		}
+	}
+	if _, err := os.Stat(store.legacyReviewersPath); err != nil {
+		t.Errorf("Store.Load() removed the legacy file, want it kept until the state is written")
+	}
+
+	err = store.Update(func(s *State) error {
+		s.Repository("R_2").AddReviewerSelection([]string{"dave"}, time.Now())
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Store.Update() error = %v", err)
+	}
+	if _, err := os.Stat(store.legacyReviewersPath); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Store.Update() kept the legacy file, want it removed once migrated")
+	}
+
+	got, err = store.Load()
+	if err != nil {
+		t.Fatalf("Store.Load() error = %v", err)
+	}
+	if latest := got.Repositories["R_1"].LatestReviewers(); !reflect.DeepEqual(latest, []string{"alice", "bob"}) {
+		t.Errorf("LatestReviewers() = %v, want the migrated reviewers to be kept", latest)
+	}
+	if n := len(got.Repositories["R_2"].ReviewerSelections); n != 2 {
+		t.Errorf("Store.Load() has %d selections for R_2, want the migrated one and the new one", n)
+	}
+}